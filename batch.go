@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// 批量测试文件格式，每行一个字符串：
+//
+//	// 注释和空行被忽略
+//	accept: id + id * num   // 期望被接受
+//	reject: id + * num      // 期望被拒绝
+//	( id )                  // 没有标注，只报告结果
+//
+// 只有行首或空白之后的//是注释，accept: a//b 中的//是两个终结符/。
+// 目录中所有不以.开头的普通文件都按这个格式读取，按文件名排序。
+
+// sentence
+// 测试文件中的一个字符串，expect为""时没有标注
+type sentence struct {
+	file   string
+	line   int
+	input  string
+	expect string
+}
+
+// readSentences
+// 读取一个测试文件中的所有字符串
+func readSentences(path string) ([]sentence, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var result []sentence
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(stripComment(scanner.Text()))
+		if text == "" {
+			continue
+		}
+		s := sentence{file: path, line: line, input: text}
+		for _, expect := range []string{"accept", "reject"} {
+			if rest, ok := strings.CutPrefix(text, expect+":"); ok {
+				s.expect = expect
+				s.input = strings.TrimSpace(rest)
+				break
+			}
+		}
+		result = append(result, s)
+	}
+	return result, scanner.Err()
+}
+
+// stripComment
+// 去掉行中的注释。只有行首或空白之后的//是注释，a//b这样的字符串中的/是终结符
+func stripComment(text string) string {
+	for i := strings.Index(text, "//"); i >= 0; {
+		if r, _ := utf8.DecodeLastRuneInString(text[:i]); i == 0 || unicode.IsSpace(r) {
+			return text[:i]
+		}
+		next := strings.Index(text[i+1:], "//")
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return text
+}
+
+// collectSentences
+// 读取文件或目录中的所有测试文件
+func collectSentences(paths []string) ([]sentence, error) {
+	var result []sentence
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		files := []string{path}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			files = files[:0]
+			for _, e := range entries {
+				if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+			sort.Strings(files)
+		}
+		for _, file := range files {
+			sentences, err := readSentences(file)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, sentences...)
+		}
+	}
+	return result, nil
+}
+
+func runBatch(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("batch", &opts)
+	maxK := fs.Int("k", 1, "if the grammar is not LL(1), parse with the smallest k <= this limit")
+	verbose := fs.Bool("v", false, "also print the sentences that pass")
+	if err := fs.Parse(args); err != nil {
+		return exitInvalid
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return exitInvalid
+	}
+	g, isLL1, ok := initGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	parse := g.Parse
+	if !isLL1 {
+		var report ll1.KReport
+		if *maxK > 1 {
+			report = g.MinimalK(*maxK)
+		}
+		if !report.Deterministic {
+			PrintLL1(g)
+			return exitInvalid
+		}
+		parse = report.Table.Parse
+	}
+	sentences, err := collectSentences(fs.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+
+	passed, failed, unannotated := 0, 0, 0
+	for _, s := range sentences {
+		got, detail := "accept", ""
+		if tokens, err := g.Tokenize(s.input); err != nil {
+			got, detail = "reject", err.Error()
+		} else if result := parse(tokens); !result.Accepted {
+			got, detail = "reject", result.Errors[0].Error()
+		}
+		status := "PASS"
+		switch {
+		case s.expect == "":
+			status = "----"
+			unannotated++
+		case s.expect == got:
+			passed++
+		default:
+			status = "FAIL"
+			failed++
+		}
+		if status == "FAIL" || *verbose {
+			fmt.Printf("%s %s:%d: %s: %s", status, s.file, s.line, got, s.input)
+			if detail != "" {
+				fmt.Printf(" (%s)", detail)
+			}
+			fmt.Println()
+		}
+	}
+	fmt.Printf("%d sentences: %d passed, %d failed, %d not annotated\n", len(sentences), passed, failed, unannotated)
+	if failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
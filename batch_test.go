@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"// comment", ""},
+		{"accept: a + b // comment", "accept: a + b "},
+		{"accept: a//b", "accept: a//b"},
+		{"accept: a//b // comment", "accept: a//b "},
+		{"accept: a // b//c", "accept: a "},
+		{"reject: à// b", "reject: à// b"},
+		{"a\t// comment", "a\t"},
+	}
+	for _, tt := range tests {
+		if got := stripComment(tt.line); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestReadSentencesSlashTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "div.txt")
+	src := "// division\naccept: a//b\nreject: a/ // trailing /\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sentences, err := readSentences(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sentence{
+		{file: path, line: 2, input: "a//b", expect: "accept"},
+		{file: path, line: 3, input: "a/", expect: "reject"},
+	}
+	if len(sentences) != len(want) {
+		t.Fatalf("got %d sentences %v, want %v", len(sentences), sentences, want)
+	}
+	for i := range want {
+		if sentences[i] != want[i] {
+			t.Errorf("sentence %d = %+v, want %+v", i, sentences[i], want[i])
+		}
+	}
+}
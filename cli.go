@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/codegen"
+	"github.com/wrilove/Table-drives-LL-1-parser/export"
+	"github.com/wrilove/Table-drives-LL-1-parser/importer"
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// 子命令的退出码
+const (
+	exitOK      = 0 // 成功，文法是LL(1)文法，输入串被接受
+	exitFailed  = 1 // check的文法不是LL(1)文法，parse的输入串有错误，或batch有没有通过的字符串
+	exitInvalid = 2 // 命令行用法错误，文法文件无法读取、解析、检查不通过，或parse的文法没有分析表
+)
+
+// command
+// 非交互的子命令，run返回退出码
+type command struct {
+	usage string
+	help  string
+	run   func(args []string) int
+}
+
+// commands 在init中初始化，子命令的参数集要引用commands中的用法
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"check": {
+			usage: "check [-reduce] [-end marker] [-k limit] grammar-file",
+			help:  "report conflicts; exit 0 if the grammar is LL(1), 1 if not",
+			run:   runCheck,
+		},
+		"sets": {
+			usage: "sets [-reduce] [-end marker] grammar-file",
+			help:  "print the nullable, FIRST, FOLLOW and SELECT sets",
+			run:   runSets,
+		},
+		"table": {
+			usage: "table [-reduce] [-end marker] [-format text|fmt] [-o file] grammar-file",
+			help:  "print the predict table, or export the tables in another format",
+			run:   runTable,
+		},
+		"transform": {
+			usage: "transform [-reduce] [-end marker] [-original] [-format text|bnf|ebnf|antlr] [-o file] grammar-file",
+			help:  "print the grammar after left-recursion elimination and left factoring, or write it in another notation",
+			run:   runTransform,
+		},
+		"parse": {
+			usage: "parse [-reduce] [-end marker] [-k limit] [-trace json|csv [-o file]] grammar-file input-file",
+			help:  "parse the input file (- for stdin); exit 0 if accepted, 1 if not",
+			run:   runParse,
+		},
+		"batch": {
+			usage: "batch [-reduce] [-end marker] [-k limit] [-v] grammar-file file-or-dir...",
+			help:  "parse every sentence in the files; exit 0 if all annotated sentences pass, 1 if not",
+			run:   runBatch,
+		},
+		"generate": {
+			usage: "generate [-reduce] [-end marker] -o file.go [-package name] [-style table|descent] grammar-file",
+			help:  "generate a standalone Go parser",
+			run:   runGenerate,
+		},
+	}
+}
+
+// commandUsage
+// 打印所有子命令的用法
+func commandUsage() {
+	out := flag.CommandLine.Output()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s %s\n  \t%s\n", os.Args[0], commands[name].usage, commands[name].help)
+	}
+}
+
+// grammarOptions
+// 所有子命令共有的读取文法的参数
+type grammarOptions struct {
+	reduce bool
+	end    string
+}
+
+// newFlagSet
+// 创建子命令的参数集，所有子命令都支持-reduce和-end
+func newFlagSet(name string, opts *grammarOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&opts.reduce, "reduce", false, "remove unproductive and unreachable symbols before computing the sets")
+	fs.StringVar(&opts.end, "end", "", "end-of-input marker, e.g. $ (default: %end in the grammar file, or "+ll1.DefaultEndMarker+")")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s\n", os.Args[0], commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs
+// 解析子命令的参数，要求剩下n个位置参数
+func parseArgs(fs *flag.FlagSet, args []string, n int) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return false
+	}
+	return true
+}
+
+// loadGrammar
+// 读取并检查文法文件，出错时打印错误；-end指定的结束符优先于文法文件中的%end
+func loadGrammar(path string, opts grammarOptions) (*ll1.Grammar, bool) {
+	g, err := importer.LoadFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, false
+	}
+	if opts.end != "" {
+		g.End = opts.end
+	}
+	if err := g.Validate(); err != nil {
+		printErrors(path, err)
+		return nil, false
+	}
+	g.RemoveUseless = opts.reduce
+	return g, true
+}
+
+// initGrammar
+// 读取文法并调用GInit，返回文法和是否构造了预测分析表
+func initGrammar(path string, opts grammarOptions) (*ll1.Grammar, bool, bool) {
+	g, ok := loadGrammar(path, opts)
+	if !ok {
+		return nil, false, false
+	}
+	isLL1, err := g.GInit()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return nil, false, false
+	}
+	return g, isLL1, true
+}
+
+func runCheck(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("check", &opts)
+	maxK := fs.Int("k", 1, "if the grammar is not LL(1), look for the smallest k <= this limit")
+	if !parseArgs(fs, args, 1) {
+		return exitInvalid
+	}
+	g, ok := loadGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	if useless := g.UselessSymbols(); !useless.Empty() {
+		PrintUselessSymbols(useless)
+	}
+	isLL1, err := g.GInit()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		return exitInvalid
+	}
+	PrintLL1(g)
+	if isLL1 {
+		return exitOK
+	}
+	if *maxK > 1 {
+		if report := g.MinimalK(*maxK); report.Deterministic {
+			fmt.Printf("The grammar is LL(%d)\n", report.K)
+			return exitOK
+		}
+		fmt.Printf("The grammar is not LL(k) for any k <= %d\n", *maxK)
+	}
+	return exitFailed
+}
+
+func runSets(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("sets", &opts)
+	if !parseArgs(fs, args, 1) {
+		return exitInvalid
+	}
+	g, _, ok := initGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	PrintNullableTable(g)
+	fmt.Println()
+	PrintFirstSet(g)
+	fmt.Println()
+	PrintFollowSet(g)
+	fmt.Println()
+	PrintSelectSet(g)
+	return exitOK
+}
+
+func runTable(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("table", &opts)
+	format := fs.String("format", "text", "text, or export the tables as "+strings.Join(export.Formats, ", "))
+	output := fs.String("o", "", "write the exported tables to this file instead of stdout")
+	if !parseArgs(fs, args, 1) {
+		return exitInvalid
+	}
+	if *format != "text" {
+		g, ok := loadGrammar(fs.Arg(0), opts)
+		if !ok {
+			return exitInvalid
+		}
+		isLL1, err := exportTables(g, *format, *output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+		if !isLL1 {
+			fmt.Fprintln(os.Stderr, "grammar is not LL(1), see the conflicts in the exported tables")
+			return exitFailed
+		}
+		return exitOK
+	}
+	g, isLL1, ok := initGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	if !isLL1 {
+		PrintLL1(g)
+		return exitFailed
+	}
+	PrintPredict(g)
+	return exitOK
+}
+
+func runTransform(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("transform", &opts)
+	format := fs.String("format", "text", "write the grammar as text or "+strings.Join(export.GrammarFormats, ", "))
+	output := fs.String("o", "", "write the grammar to this file instead of stdout")
+	original := fs.Bool("original", false, "write the grammar as read, without the rewrites")
+	if !parseArgs(fs, args, 1) {
+		return exitInvalid
+	}
+	var g *ll1.Grammar
+	var ok bool
+	if *original {
+		g, ok = loadGrammar(fs.Arg(0), opts)
+	} else {
+		g, _, ok = initGrammar(fs.Arg(0), opts)
+	}
+	if !ok {
+		return exitInvalid
+	}
+	if *format != "text" || *output != "" {
+		if err := writeGrammar(g, fs.Arg(0), *format, *output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+		return exitOK
+	}
+	if *original {
+		PrintGrammar("Input grammar:", g)
+		return exitOK
+	}
+	for _, rewrite := range g.Rewrites {
+		if rewrite.Changed {
+			PrintRewrite(rewrite)
+			fmt.Println()
+		}
+	}
+	PrintGrammar("Transformed grammar:", g)
+	return exitOK
+}
+
+// writeGrammar
+// 按format写出文法，path为空时写到标准输出；ANTLR文法以文法文件的文件名命名
+func writeGrammar(g *ll1.Grammar, grammarPath, format, path string) error {
+	var buf bytes.Buffer
+	if format == "text" {
+		for _, prod := range g.Productions {
+			fmt.Fprintln(&buf, prod)
+		}
+	} else {
+		name := strings.TrimSuffix(filepath.Base(grammarPath), filepath.Ext(grammarPath))
+		if err := export.WriteGrammar(&buf, g, format, grammarName(name)); err != nil {
+			return err
+		}
+	}
+	if path == "" {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// grammarName
+// ANTLR文法名必须是标识符，把其他字符换成 _
+func grammarName(name string) string {
+	r := []rune(name)
+	for i, c := range r {
+		if !unicode.IsLetter(c) && (i == 0 || !unicode.IsDigit(c)) {
+			r[i] = '_'
+		}
+	}
+	if len(r) == 0 {
+		return "Grammar"
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func runParse(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("parse", &opts)
+	maxK := fs.Int("k", 1, "if the grammar is not LL(1), parse with the smallest k <= this limit")
+	trace := fs.String("trace", "", "write the parse steps as "+strings.Join(export.TraceFormats, ", ")+" instead of the readable output")
+	output := fs.String("o", "", "write the trace to this file instead of stdout")
+	if !parseArgs(fs, args, 2) {
+		return exitInvalid
+	}
+	g, isLL1, ok := initGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	parse := g.Parse
+	if !isLL1 {
+		var report ll1.KReport
+		if *maxK > 1 {
+			report = g.MinimalK(*maxK)
+		}
+		if !report.Deterministic {
+			PrintLL1(g)
+			return exitInvalid
+		}
+		parse = report.Table.Parse
+	}
+	var src []byte
+	var err error
+	if fs.Arg(1) == "-" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(fs.Arg(1))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitInvalid
+	}
+	// 不去掉输入两端的空白：文法可以有换行这样的终结符（例如导入的yacc文法中的'\n'），
+	// 其他文法的词法规则会跳过它们，去掉空白的input只用于显示
+	input := strings.TrimSpace(string(src))
+	tokens, err := g.Tokenize(string(src))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s:%v\n", fs.Arg(1), err)
+		return exitFailed
+	}
+	result := parse(tokens)
+	if *trace != "" {
+		if err := writeTrace(input, result, *trace, *output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInvalid
+		}
+		if !result.Accepted {
+			return exitFailed
+		}
+		return exitOK
+	}
+	PrintParse(input, result)
+	if !result.Accepted {
+		return exitFailed
+	}
+	fmt.Println()
+	PrintTree(result.Tree)
+	return exitOK
+}
+
+// writeTrace
+// 按format导出分析过程，path为空时写到标准输出
+func writeTrace(input string, result ll1.ParseResult, format, path string) error {
+	var buf bytes.Buffer
+	if err := export.NewTrace(input, result).Write(&buf, format); err != nil {
+		return err
+	}
+	if path == "" {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func runGenerate(args []string) int {
+	var opts grammarOptions
+	fs := newFlagSet("generate", &opts)
+	output := fs.String("o", "", "write the generated parser to this file (required)")
+	pkg := fs.String("package", "parser", "package name of the generated parser")
+	style := fs.String("style", "table", "style of the generated parser: table or descent")
+	if !parseArgs(fs, args, 1) {
+		return exitInvalid
+	}
+	if *output == "" {
+		fs.Usage()
+		return exitInvalid
+	}
+	g, ok := loadGrammar(fs.Arg(0), opts)
+	if !ok {
+		return exitInvalid
+	}
+	if err := generate(g, *output, *style, codegen.Options{Package: *pkg}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,239 @@
+// Package codegen 根据GInit之后的LL(1)文法生成不依赖本工具的Go分析程序。
+package codegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"text/template"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// Options
+// 生成代码的选项，Package为空时使用"parser"
+type Options struct {
+	Package string
+}
+
+// data
+// 模板使用的数据，所有切片都按文法中的顺序排列，保证生成的代码是确定的
+type data struct {
+	Package      string
+	Start        string
+	End          string
+	NonTerminals []string
+	Rows         []row
+}
+
+// row
+// 预测分析表的一行，Entries的右部中去掉了ε，ε产生式的右部为空
+type row struct {
+	NonTerminal string
+	Entries     []entry
+	Expected    []string
+}
+
+type entry struct {
+	Terminal string
+	Right    []string
+}
+
+// newData
+// 从文法中收集生成代码需要的数据，文法必须已经由GInit构造出预测分析表
+func newData(g *ll1.Grammar, opts Options) (*data, error) {
+	if g.Predict == nil {
+		return nil, errors.New("grammar has no predict table, run GInit on an LL(1) grammar first")
+	}
+	d := &data{Package: opts.Package, Start: g.Start.Value, End: g.EndSymbol().Value}
+	if d.Package == "" {
+		d.Package = "parser"
+	}
+	if !token.IsIdentifier(d.Package) {
+		return nil, fmt.Errorf("invalid package name %q", d.Package)
+	}
+	terminals := append(g.GetTerminals(), g.EndSymbol())
+	for _, nt := range g.GetNonTerminals() {
+		d.NonTerminals = append(d.NonTerminals, nt.Value)
+		r := row{NonTerminal: nt.Value}
+		for _, t := range terminals {
+			prod, ok := g.Predict[nt][t]
+			if !ok {
+				continue
+			}
+			e := entry{Terminal: t.Value, Right: []string{}}
+			for _, s := range prod.Right[0].Symbols {
+				if !s.IsEpsilon() {
+					e.Right = append(e.Right, s.Value)
+				}
+			}
+			r.Entries = append(r.Entries, e)
+			r.Expected = append(r.Expected, t.Value)
+		}
+		d.Rows = append(d.Rows, r)
+	}
+	return d, nil
+}
+
+// execute
+// 执行模板并用gofmt格式化生成的代码
+func execute(tmpl *template.Template, d *data) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return formatSource(buf.Bytes())
+}
+
+func formatSource(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not parse: %w", err)
+	}
+	return formatted, nil
+}
+
+// GenerateTable
+// 生成表驱动的分析程序：预测分析表是静态数据，Parse与ll1.Grammar.Parse的分析过程相同，
+// 返回与ll1.Node形状相同的分析树，遇到第一个错误时停止
+func GenerateTable(g *ll1.Grammar, opts Options) ([]byte, error) {
+	d, err := newData(g, opts)
+	if err != nil {
+		return nil, err
+	}
+	return execute(tableTemplate, d)
+}
+
+var funcs = template.FuncMap{
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}
+
+// common 两种生成的分析程序共用的类型
+const common = `
+// Token 输入中的单词，Kind是对应的终结符
+type Token struct {
+	Kind   string
+	Text   string
+	Line   int
+	Column int
+}
+
+// Node 分析树的结点，非终结符结点的Children是展开时所用备选项中的符号，
+// 终结符叶子的Token是匹配到的单词，ε展开时得到一个Symbol为"ε"的叶子
+type Node struct {
+	Symbol   string
+	Token    Token
+	Children []*Node
+}
+
+// Error 语法错误，Token是出错时输入中的当前单词，Expected是此时可以接受的终结符
+type Error struct {
+	Token    Token
+	Expected []string
+}
+
+func (e *Error) Error() string {
+	found := fmt.Sprintf("unexpected %q", e.Token.Text)
+	if e.Token.Kind == EndMarker {
+		found = "unexpected end of input"
+	}
+	return fmt.Sprintf("%d:%d: %s, expected %s", e.Token.Line, e.Token.Column, found, strings.Join(e.Expected, ", "))
+}
+
+// endToken 输入末尾的结束符，位置紧跟在最后一个单词之后
+func endToken(tokens []Token) Token {
+	end := Token{Kind: EndMarker, Line: 1, Column: 1}
+	if len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		end.Line, end.Column = last.Line, last.Column+utf8.RuneCountInString(last.Text)
+	}
+	return end
+}
+`
+
+var tableTemplate = template.Must(template.New("table").Funcs(funcs).Parse(`// Code generated by Table-drives-LL-1-parser; DO NOT EDIT.
+
+// Package {{.Package}} 是由文法生成的表驱动LL(1)分析程序。
+package {{.Package}}
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Start 文法的开始符
+const Start = {{quote .Start}}
+
+// EndMarker 输入结束符
+const EndMarker = {{quote .End}}
+
+var nonTerminals = map[string]bool{
+{{- range .NonTerminals}}
+	{{quote .}}: true,
+{{- end}}
+}
+
+// predict 预测分析表 M[非终结符][终结符] = 产生式右部，ε产生式的右部为空
+var predict = map[string]map[string][]string{
+{{- range .Rows}}
+	{{quote .NonTerminal}}: {
+	{{- range .Entries}}
+		{{quote .Terminal}}: { {{- range $i, $s := .Right}}{{if $i}}, {{end}}{{quote $s}}{{end -}} },
+	{{- end}}
+	},
+{{- end}}
+}
+
+// expected 预测分析表每一行中有产生式的终结符
+var expected = map[string][]string{
+{{- range .Rows}}
+	{{quote .NonTerminal}}: { {{- range $i, $s := .Expected}}{{if $i}}, {{end}}{{quote $s}}{{end -}} },
+{{- end}}
+}
+` + common + `
+// Parse 用预测分析表分析单词序列，成功时返回分析树
+func Parse(tokens []Token) (*Node, error) {
+	input := append(append([]Token(nil), tokens...), endToken(tokens))
+	root := &Node{Symbol: Start}
+	end := &Node{Symbol: EndMarker}
+	stack := []*Node{end, root}
+	for {
+		top := stack[len(stack)-1]
+		tok := input[0]
+		if top.Symbol == EndMarker {
+			if tok.Kind == EndMarker {
+				return root, nil
+			}
+			return nil, &Error{Token: tok, Expected: []string{EndMarker}}
+		}
+		stack = stack[:len(stack)-1]
+		if !nonTerminals[top.Symbol] {
+			if top.Symbol != tok.Kind {
+				return nil, &Error{Token: tok, Expected: []string{top.Symbol}}
+			}
+			top.Token = tok
+			input = input[1:]
+			continue
+		}
+		right, ok := predict[top.Symbol][tok.Kind]
+		if !ok {
+			return nil, &Error{Token: tok, Expected: expected[top.Symbol]}
+		}
+		if len(right) == 0 {
+			epsilon := &Node{Symbol: "ε"}
+			top.Children = []*Node{epsilon}
+			continue
+		}
+		top.Children = make([]*Node, len(right))
+		for i, s := range right {
+			top.Children[i] = &Node{Symbol: s}
+		}
+		for i := len(right) - 1; i >= 0; i-- {
+			stack = append(stack, top.Children[i])
+		}
+	}
+}
+`))
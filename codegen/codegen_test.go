@@ -0,0 +1,187 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// harness 同时调用两种生成的分析程序，每个输入串输出一行结果：分析树或错误信息
+const harness = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gentest/descent"
+	"gentest/table"
+)
+
+func tree(symbol, text string, children []string) string {
+	if len(children) == 0 {
+		if text != "" {
+			return text
+		}
+		return symbol
+	}
+	return symbol + "(" + strings.Join(children, " ") + ")"
+}
+
+func tableTree(n *table.Node) string {
+	var children []string
+	for _, c := range n.Children {
+		children = append(children, tableTree(c))
+	}
+	return tree(n.Symbol, n.Token.Text, children)
+}
+
+func descentTree(n *descent.Node) string {
+	var children []string
+	for _, c := range n.Children {
+		children = append(children, descentTree(c))
+	}
+	return tree(n.Symbol, n.Token.Text, children)
+}
+
+func main() {
+	var sentences [][]table.Token
+	if err := json.NewDecoder(os.Stdin).Decode(&sentences); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, tokens := range sentences {
+		if root, err := table.Parse(tokens); err != nil {
+			fmt.Println("reject", err)
+		} else {
+			fmt.Println("accept", tableTree(root))
+		}
+		converted := make([]descent.Token, len(tokens))
+		for i, tok := range tokens {
+			converted[i] = descent.Token(tok)
+		}
+		if root, err := descent.Parse(converted); err != nil {
+			fmt.Println("reject", err)
+		} else {
+			fmt.Println("accept", descentTree(root))
+		}
+	}
+}
+`
+
+// TestGeneratedParsersAgree
+// 为examples/calc.ll1生成表驱动和递归下降两种分析程序，编译后分析同一组输入串，
+// 两者的分析树和错误信息应当相同，是否接受应当与ll1.Grammar.Parse一致
+func TestGeneratedParsersAgree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds generated code")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	g, err := ll1.LoadGrammarFile(filepath.Join("..", "examples", "calc.ll1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := g.GInit(); err != nil || !ok {
+		t.Fatalf("GInit() = %v, %v", ok, err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod":  []byte("module gentest\n\ngo 1.21\n"),
+		"main.go": []byte(harness),
+	}
+	for name, generate := range map[string]func(*ll1.Grammar, Options) ([]byte, error){
+		"table":   GenerateTable,
+		"descent": GenerateDescent,
+	} {
+		src, err := generate(g, Options{Package: name})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		files[filepath.Join(name, "parser.go")] = src
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bin := filepath.Join(dir, "harness")
+	build := exec.Command(goTool, "build", "-o", bin, ".")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	sentences := []struct {
+		input  string
+		accept bool
+	}{
+		{"x", true},
+		{"1 + 2 * x", true},
+		{"sqrt(2) / (a - b)", true},
+		{"((1.5))  # comment", true},
+		{"a * b * c + d - sqrt(sqrt(e))", true},
+		{"", false},
+		{"1 +", false},
+		{"(1", false},
+		{"sqrt 2", false},
+		{"* x", false},
+		{"1 2", false},
+		{"a + ) b", false},
+	}
+	type token struct {
+		Kind, Text   string
+		Line, Column int
+	}
+	var input [][]token
+	for _, s := range sentences {
+		tokens, err := g.Tokenize(s.input)
+		if err != nil {
+			t.Fatalf("%q: %v", s.input, err)
+		}
+		if got := g.Parse(tokens).Accepted; got != s.accept {
+			t.Fatalf("ll1 Parse(%q).Accepted = %v, want %v", s.input, got, s.accept)
+		}
+		converted := []token{}
+		for _, tok := range tokens {
+			converted = append(converted, token{Kind: tok.Kind, Text: tok.Text, Line: tok.Pos.Line, Column: tok.Pos.Column})
+		}
+		input = append(input, converted)
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := exec.Command(bin)
+	run.Stdin = strings.NewReader(string(data))
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("harness: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(lines) != 2*len(sentences) {
+		t.Fatalf("harness printed %d lines, want %d:\n%s", len(lines), 2*len(sentences), out)
+	}
+	for i, s := range sentences {
+		tableResult, descentResult := lines[2*i], lines[2*i+1]
+		if tableResult != descentResult {
+			t.Errorf("%q:\ntable:   %s\ndescent: %s", s.input, tableResult, descentResult)
+		}
+		if accepted := strings.HasPrefix(tableResult, "accept "); accepted != s.accept {
+			t.Errorf("%q: generated parsers: %s, want accept=%v", s.input, tableResult, s.accept)
+		}
+	}
+}
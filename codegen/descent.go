@@ -0,0 +1,218 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// descentData
+// 递归下降分析程序模板使用的数据
+type descentData struct {
+	Package string
+	Start   string
+	End     string
+	Funcs   []descentFunc
+}
+
+// descentFunc
+// 一个非终结符对应的分析函数，每个备选项是switch中的一个case
+type descentFunc struct {
+	Name        string
+	NonTerminal string
+	Cases       []descentCase
+	Expected    []string
+}
+
+// descentCase
+// 一个备选项：Lookahead是选择这个备选项的终结符（即预测分析表中这个备选项所在的列），
+// Symbols为空表示ε备选项
+type descentCase struct {
+	Production string
+	Lookahead  []string
+	Symbols    []descentSymbol
+}
+
+// descentSymbol
+// 备选项中的符号，Func不为空时是非终结符，调用对应的分析函数
+type descentSymbol struct {
+	Value string
+	Func  string
+}
+
+// GenerateDescent
+// 生成递归下降的分析程序：每个非终结符一个函数，按预测分析表根据当前单词选择备选项，
+// ε备选项由follow集中的终结符选择。Parse返回与表驱动分析程序形状相同的分析树
+func GenerateDescent(g *ll1.Grammar, opts Options) ([]byte, error) {
+	d, err := newData(g, opts)
+	if err != nil {
+		return nil, err
+	}
+	dd := &descentData{Package: d.Package, Start: d.Start, End: d.End}
+	names := funcNames(d.NonTerminals)
+	for _, nt := range g.GetNonTerminals() {
+		f := descentFunc{Name: names[nt.Value], NonTerminal: nt.Value}
+		index := make(map[string]int)
+		for _, t := range append(g.GetTerminals(), g.EndSymbol()) {
+			prod, ok := g.Predict[nt][t]
+			if !ok {
+				continue
+			}
+			f.Expected = append(f.Expected, t.Value)
+			key := prod.String()
+			i, ok := index[key]
+			if !ok {
+				i = len(f.Cases)
+				index[key] = i
+				c := descentCase{Production: key}
+				for _, s := range prod.Right[0].Symbols {
+					if !s.IsEpsilon() {
+						c.Symbols = append(c.Symbols, descentSymbol{Value: s.Value, Func: names[s.Value]})
+					}
+				}
+				f.Cases = append(f.Cases, c)
+			}
+			f.Cases[i].Lookahead = append(f.Cases[i].Lookahead, t.Value)
+		}
+		dd.Funcs = append(dd.Funcs, f)
+	}
+	var startFunc string
+	for _, f := range dd.Funcs {
+		if f.NonTerminal == dd.Start {
+			startFunc = f.Name
+		}
+	}
+	if startFunc == "" {
+		return nil, fmt.Errorf("start symbol %s has no production", dd.Start)
+	}
+	return executeDescent(dd, startFunc)
+}
+
+func executeDescent(dd *descentData, startFunc string) ([]byte, error) {
+	var buf strings.Builder
+	err := descentTemplate.Execute(&buf, struct {
+		*descentData
+		StartFunc string
+	}{dd, startFunc})
+	if err != nil {
+		return nil, err
+	}
+	return formatSource([]byte(buf.String()))
+}
+
+// funcNames
+// 为每个非终结符生成分析函数的名字，例如 expr_tail -> parseExprTail，expr' -> parseExprPrime，
+// 名字重复时在后面加上编号
+func funcNames(nonTerminals []string) map[string]string {
+	names := make(map[string]string)
+	used := make(map[string]bool)
+	for _, nt := range nonTerminals {
+		var sb strings.Builder
+		sb.WriteString("parse")
+		upper := true
+		for _, r := range nt {
+			switch {
+			case r == '\'':
+				sb.WriteString("Prime")
+				upper = true
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if upper {
+					r = unicode.ToUpper(r)
+				}
+				sb.WriteRune(r)
+				upper = false
+			default:
+				upper = true
+			}
+		}
+		name := sb.String()
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", sb.String(), i)
+		}
+		used[name] = true
+		names[nt] = name
+	}
+	return names
+}
+
+var descentTemplate = template.Must(template.New("descent").Funcs(funcs).Parse(`// Code generated by Table-drives-LL-1-parser; DO NOT EDIT.
+
+// Package {{.Package}} 是由文法生成的递归下降LL(1)分析程序。
+package {{.Package}}
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Start 文法的开始符
+const Start = {{quote .Start}}
+
+// EndMarker 输入结束符
+const EndMarker = {{quote .End}}
+` + common + `
+type parser struct {
+	input []Token
+}
+
+func (p *parser) peek() Token {
+	return p.input[0]
+}
+
+// match 当前单词是终结符kind时把对应的叶子加到parent下并读入下一个单词
+func (p *parser) match(parent *Node, kind string) error {
+	tok := p.peek()
+	if tok.Kind != kind {
+		return &Error{Token: tok, Expected: []string{kind}}
+	}
+	p.input = p.input[1:]
+	parent.Children = append(parent.Children, &Node{Symbol: kind, Token: tok})
+	return nil
+}
+
+// Parse 用递归下降的方式分析单词序列，成功时返回分析树
+func Parse(tokens []Token) (*Node, error) {
+	p := &parser{input: append(append([]Token(nil), tokens...), endToken(tokens))}
+	root := &Node{}
+	if err := p.{{.StartFunc}}(root); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != EndMarker {
+		return nil, &Error{Token: tok, Expected: []string{EndMarker}}
+	}
+	return root.Children[0], nil
+}
+{{range .Funcs}}
+// {{.Name}} 分析非终结符 {{.NonTerminal}}，把得到的结点加到parent下
+func (p *parser) {{.Name}}(parent *Node) error {
+	node := &Node{Symbol: {{quote .NonTerminal}}}
+	parent.Children = append(parent.Children, node)
+	switch p.peek().Kind {
+	{{- range .Cases}}
+	case {{range $i, $t := .Lookahead}}{{if $i}}, {{end}}{{quote $t}}{{end}}:
+		// {{.Production}}
+		{{- if not .Symbols}}
+		node.Children = append(node.Children, &Node{Symbol: "ε"})
+		{{- end}}
+		{{- range .Symbols}}
+		{{- if .Func}}
+		if err := p.{{.Func}}(node); err != nil {
+			return err
+		}
+		{{- else}}
+		if err := p.match(node, {{quote .Value}}); err != nil {
+			return err
+		}
+		{{- end}}
+		{{- end}}
+	{{- end}}
+	default:
+		return &Error{Token: p.peek(), Expected: []string{ {{- range $i, $t := .Expected}}{{if $i}}, {{end}}{{quote $t}}{{end -}} }}
+	}
+	return nil
+}
+{{end}}`))
@@ -0,0 +1,46 @@
+/* yacc 文法：go run . check examples/calc.y */
+%{
+#include <stdio.h>
+int yylex(void);
+%}
+
+%union { double value; }
+
+%token <value> NUM
+%token LET "let" ASSIGN "="
+%token ID "identifier"
+%left '+' '-'
+%left '*' '/'
+%start input
+
+%%
+
+input
+    : %empty
+    | input line
+    ;
+
+line: '\n'
+    | exp '\n'                 { printf("%g\n", $1); }
+    | LET ID "=" exp '\n'
+    ;
+
+exp : term
+    | exp '+' term             { $$ = $1 + $3; }
+    | exp '-' term             { $$ = $1 - $3; }
+
+term: factor
+    | term '*' factor          { $$ = $1 * $3; }
+    | term '/' factor          { $$ = $1 / $3; }
+    ;
+
+factor
+    : NUM
+    | "identifier"
+    | '(' exp ')'
+    | '-' factor %prec '*'
+    ;
+
+%%
+
+int main(void) { return yyparse(); }
@@ -0,0 +1,37 @@
+// ANTLR 4 文法：go run . check examples/expr.g4
+grammar Expr;
+
+options { language = Go; }
+
+@header {
+import "strconv"
+}
+
+prog : stat+ EOF ;
+
+stat
+    : expr ';'              # printExpr
+    | ID '=' expr ';'       # assign
+    ;
+
+expr
+    : term (op=(PLUS | MINUS) term)*
+    ;
+
+term : factor ((MUL | DIV) factor)* ;
+
+factor
+    : INT                   { $value = strconv.Atoi($INT.text) }
+    | '(' expr ')'
+    | ID args?
+    ;
+
+args : '(' (expr (',' expr)*)? ')' ;
+
+PLUS  : '+' ;
+MINUS : '-' ;
+MUL   : '*' ;
+DIV   : '/' ;
+ID    : [a-zA-Z_] [a-zA-Z_0-9]* ;
+INT   : [0-9]+ ;
+WS    : [ \t\r\n]+ -> skip ;
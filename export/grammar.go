@@ -0,0 +1,378 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// 文法的写出
+//
+// 任何文法（GInit之前的原始文法或改写后的文法）都可以写成BNF、W3C风格的EBNF或ANTLR 4的.g4骨架。
+// 产生式按文法中的顺序写出；左部出现过的符号是非终结符，其余符号（ε除外）是终结符。
+
+// GrammarFormats 文法支持的写出格式
+var GrammarFormats = []string{"bnf", "ebnf", "antlr"}
+
+// WriteGrammar
+// 按format写出文法，format为GrammarFormats之一，g4是antlr的别名；name是ANTLR文法的名字
+func WriteGrammar(w io.Writer, g *ll1.Grammar, format, name string) error {
+	switch format {
+	case "bnf":
+		return WriteBNF(w, g)
+	case "ebnf":
+		return WriteEBNF(w, g)
+	case "antlr", "g4":
+		return WriteANTLR(w, g, name)
+	}
+	return fmt.Errorf("unknown grammar format %q, expected one of %s", format, strings.Join(GrammarFormats, ", "))
+}
+
+// nonTerminalSet
+// 产生式左部出现过的符号
+func nonTerminalSet(g *ll1.Grammar) map[string]bool {
+	result := make(map[string]bool)
+	for _, prod := range g.Productions {
+		result[prod.Left.Value] = true
+	}
+	return result
+}
+
+// isEpsilon
+// 备选项是否只有ε
+func isEpsilon(alt ll1.Alternative) bool {
+	return len(alt.Symbols) == 1 && alt.Symbols[0].IsEpsilon()
+}
+
+// quoteTerminal
+// 用文本中没有出现的引号括起终结符，两种引号都出现时用双引号；
+// 按ll1.QuoteTerminal转义反斜杠、引号和控制字符，写出的终结符可以被ll1.ParseGrammar读回
+func quoteTerminal(text string) string {
+	if strings.Contains(text, `"`) && !strings.Contains(text, `'`) {
+		return ll1.QuoteTerminal(text, '\'')
+	}
+	return ll1.QuoteTerminal(text, '"')
+}
+
+// isPatternToken
+// 终结符是否绑定了正则表达式，这样的终结符是一类单词，只能按名字引用
+func isPatternToken(g *ll1.Grammar, terminal string) bool {
+	_, pattern, bound := lookupBinding(g, terminal)
+	return bound && pattern != ""
+}
+
+// WriteBNF
+// 写成BNF：<expr> ::= <term> <expr'> | "+" | ，非终结符用尖括号括起，终结符用引号括起，
+// 绑定了正则表达式的终结符写成不带引号的名字，ε写成空的备选项。写出的文法可以用ll1.ParseGrammar读回
+func WriteBNF(w io.Writer, g *ll1.Grammar) error {
+	bw := bufio.NewWriter(w)
+	nonTerminals := nonTerminalSet(g)
+	for _, prod := range g.Productions {
+		alts := make([]string, len(prod.Right))
+		for i, alt := range prod.Right {
+			if isEpsilon(alt) {
+				continue
+			}
+			symbols := make([]string, len(alt.Symbols))
+			for j, s := range alt.Symbols {
+				switch {
+				case nonTerminals[s.Value]:
+					symbols[j] = "<" + s.Value + ">"
+				case isPatternToken(g, s.Value):
+					symbols[j] = s.Value
+				default:
+					symbols[j] = quoteTerminal(s.Value)
+				}
+			}
+			alts[i] = strings.Join(symbols, " ")
+		}
+		line := fmt.Sprintf("<%s> ::= %s", prod.Left.Value, strings.Join(alts, " | "))
+		fmt.Fprintln(bw, strings.TrimRight(line, " "))
+	}
+	return bw.Flush()
+}
+
+// names
+// 为非终结符或终结符生成目标格式中合法且互不相同的名字
+type names struct {
+	byValue map[string]string
+	used    map[string]bool
+}
+
+func newNames(reserved ...string) *names {
+	n := &names{byValue: make(map[string]string), used: make(map[string]bool)}
+	for _, r := range reserved {
+		n.used[r] = true
+	}
+	return n
+}
+
+// get
+// 返回value对应的名字，第一次出现时用mangle生成，已被占用时在后面加 _
+func (n *names) get(value string, mangle func(string) string) string {
+	if name, ok := n.byValue[value]; ok {
+		return name
+	}
+	name := mangle(value)
+	for n.used[name] {
+		name += "_"
+	}
+	n.used[name] = true
+	n.byValue[value] = name
+	return name
+}
+
+// identifier
+// 把名字中标识符不允许的字符换掉：' 写作 _prime，其余写作 _
+func identifier(value string) string {
+	var sb strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\'':
+			sb.WriteString("_prime")
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// WriteEBNF
+// 写成W3C XML规范风格的EBNF：expr ::= term expr_prime ，终结符用引号括起，绑定了正则表达式的终结符写成名字；
+// 有ε备选项的产生式写成 ( α | β )? ，只有ε时写成空的注释
+func WriteEBNF(w io.Writer, g *ll1.Grammar) error {
+	bw := bufio.NewWriter(w)
+	nonTerminals := nonTerminalSet(g)
+	ruleNames := newNames()
+	// 先为所有左部取名，保证名字与产生式的顺序无关
+	for _, prod := range g.Productions {
+		ruleNames.get(prod.Left.Value, identifier)
+	}
+	for _, prod := range g.Productions {
+		var alts []string
+		optional := false
+		for _, alt := range prod.Right {
+			if isEpsilon(alt) {
+				optional = true
+				continue
+			}
+			symbols := make([]string, len(alt.Symbols))
+			for j, s := range alt.Symbols {
+				if nonTerminals[s.Value] || isPatternToken(g, s.Value) {
+					// 记号的名字与规则名一起分配，不会与规则重名
+					symbols[j] = ruleNames.get(s.Value, identifier)
+				} else {
+					symbols[j] = quoteTerminal(s.Value)
+				}
+			}
+			alts = append(alts, strings.Join(symbols, " "))
+		}
+		right := strings.Join(alts, " | ")
+		switch {
+		case optional && len(alts) == 0:
+			right = "/* ε */"
+		case optional:
+			right = "( " + right + " )?"
+		}
+		fmt.Fprintf(bw, "%s ::= %s\n", ruleNames.get(prod.Left.Value, identifier), right)
+	}
+	return bw.Flush()
+}
+
+// antlrReserved ANTLR 4的关键字，不能作为规则名
+var antlrReserved = []string{
+	"import", "fragment", "lexer", "parser", "grammar", "returns", "locals", "throws",
+	"catch", "finally", "mode", "options", "tokens", "channels", "EOF",
+}
+
+// WriteANTLR
+// 写成ANTLR 4的组合文法骨架：非终结符是首字母小写的语法规则，
+// 不是标识符的终结符和绑定了原文的终结符写成字面量，其余终结符写成大写的记号并生成词法规则；
+// 绑定了正则表达式的记号尽量翻译为ANTLR的词法规则，不能翻译时保留原文并标出TODO
+func WriteANTLR(w io.Writer, g *ll1.Grammar, name string) error {
+	bw := bufio.NewWriter(w)
+	nonTerminals := nonTerminalSet(g)
+	ruleNames := newNames(antlrReserved...)
+	tokenNames := newNames(antlrReserved...)
+	ruleName := func(value string) string {
+		return ruleNames.get(value, func(v string) string {
+			id := identifier(v)
+			if r := []rune(id); unicode.IsUpper(r[0]) || !unicode.IsLetter(r[0]) {
+				if unicode.IsLetter(r[0]) {
+					r[0] = unicode.ToLower(r[0])
+					return string(r)
+				}
+				return "r" + id
+			}
+			return id
+		})
+	}
+	for _, prod := range g.Productions {
+		ruleName(prod.Left.Value)
+	}
+
+	// tokens 按出现顺序记录需要词法规则的记号
+	var tokens []string
+	terminal := func(value string) string {
+		literal, _, bound := lookupBinding(g, value)
+		if bound && literal != "" {
+			return antlrLiteral(literal)
+		}
+		if !bound && !ll1.IsIdentifier(value) {
+			return antlrLiteral(value)
+		}
+		if _, ok := tokenNames.byValue[value]; !ok {
+			tokens = append(tokens, value)
+		}
+		return tokenNames.get(value, func(v string) string {
+			id := strings.ToUpper(identifier(v))
+			if !unicode.IsLetter([]rune(id)[0]) {
+				id = "T" + id
+			}
+			return id
+		})
+	}
+
+	fmt.Fprintf(bw, "grammar %s;\n\n", name)
+	fmt.Fprintf(bw, "// start: %s\n\n", ruleName(g.Start.Value))
+	for _, prod := range g.Productions {
+		alts := make([]string, len(prod.Right))
+		for i, alt := range prod.Right {
+			if isEpsilon(alt) {
+				alts[i] = "/* ε */"
+				continue
+			}
+			symbols := make([]string, len(alt.Symbols))
+			for j, s := range alt.Symbols {
+				if nonTerminals[s.Value] {
+					symbols[j] = ruleName(s.Value)
+				} else {
+					symbols[j] = terminal(s.Value)
+				}
+			}
+			alts[i] = strings.Join(symbols, " ")
+		}
+		fmt.Fprintf(bw, "%s\n    : %s\n    ;\n\n", ruleName(prod.Left.Value), strings.Join(alts, "\n    | "))
+	}
+	// ANTLR在匹配长度相同时选择先定义的规则，所以按自身的名字匹配的记号（如关键字）要排在正则表达式之前
+	for _, value := range tokens {
+		if !isPatternToken(g, value) {
+			fmt.Fprintf(bw, "%s : %s ;\n", tokenNames.byValue[value], antlrLiteral(value))
+		}
+	}
+	for _, value := range tokens {
+		if !isPatternToken(g, value) {
+			continue
+		}
+		tokenName := tokenNames.byValue[value]
+		_, pattern, _ := lookupBinding(g, value)
+		if rule, ok := antlrPattern(pattern); ok {
+			fmt.Fprintf(bw, "%s : %s ;\n", tokenName, rule)
+		} else {
+			fmt.Fprintf(bw, "%s : %s ; // TODO: translate /%s/\n", tokenName, antlrLiteral(value), pattern)
+		}
+	}
+	fmt.Fprintln(bw, "WS : [ \\t\\r\\n]+ -> skip ;")
+	return bw.Flush()
+}
+
+func lookupBinding(g *ll1.Grammar, terminal string) (literal, pattern string, ok bool) {
+	if g.Lexer == nil {
+		return "", "", false
+	}
+	return g.Lexer.Binding(terminal)
+}
+
+// antlrLiteral
+// ANTLR的字面量用单引号，引号和反斜杠需要转义
+func antlrLiteral(text string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return "'" + r.Replace(text) + "'"
+}
+
+// antlrPattern
+// 把只用到字符类、分组、| * + ? 和转义字符的正则表达式翻译为ANTLR的词法规则，
+// 用到锚点、重复次数、非贪婪等其他语法时返回false
+func antlrPattern(pattern string) (string, bool) {
+	classes := map[byte]string{'d': "[0-9]", 'w': "[a-zA-Z0-9_]", 's': `[ \t\r\n]`}
+	var parts []string
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, antlrLiteral(literal.String()))
+			literal.Reset()
+		}
+	}
+	// 后缀运算符只作用于前一个字符，所以字面量中的最后一个字符要单独成为一项
+	suffix := func(op string) bool {
+		if literal.Len() > 0 {
+			s := []rune(literal.String())
+			literal.Reset()
+			literal.WriteString(string(s[:len(s)-1]))
+			flush()
+			parts = append(parts, antlrLiteral(string(s[len(s)-1])))
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		parts[len(parts)-1] += op
+		return true
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return "", false
+			}
+			i++
+			if class, ok := classes[pattern[i]]; ok {
+				flush()
+				parts = append(parts, class)
+			} else if strings.IndexByte(`.+*?()|[]{}^$\/-`, pattern[i]) >= 0 {
+				literal.WriteByte(pattern[i])
+			} else {
+				return "", false
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", false
+			}
+			class := pattern[i : i+end+2]
+			if strings.Contains(class, `\d`) || strings.Contains(class, `\w`) || strings.Contains(class, `\s`) || strings.Contains(class, "[:") {
+				return "", false
+			}
+			flush()
+			if strings.HasPrefix(class, "[^") {
+				class = "~[" + class[2:]
+			}
+			parts = append(parts, class)
+			i += end + 1
+		case '(', ')', '|':
+			if c == '(' && strings.HasPrefix(pattern[i:], "(?") {
+				return "", false
+			}
+			flush()
+			parts = append(parts, string(c))
+		case '*', '+', '?':
+			if i+1 < len(pattern) && pattern[i+1] == '?' || !suffix(string(c)) {
+				return "", false
+			}
+		case '.':
+			flush()
+			parts = append(parts, ".")
+		case '{', '}', '^', '$':
+			return "", false
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return strings.Join(parts, " "), len(parts) > 0
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/importer"
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// TestBNFRoundTrip
+// 写出的BNF用ll1.ParseGrammar读回后得到相同的产生式
+func TestBNFRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		load func() (*ll1.Grammar, error)
+	}{
+		{"backslash", func() (*ll1.Grammar, error) { return ll1.ParseGrammar(`S -> '\\' id | '\\\\' ;`) }},
+		{"both quotes", func() (*ll1.Grammar, error) { return ll1.ParseGrammar(`S -> 'a"b' | "c'd" | '\'"' | "\\\"" ;`) }},
+	}
+	for _, path := range []string{"../examples/expr.ll1", "../examples/calc.ll1", "../examples/ll1.ll1", "../examples/not_ll1.ll1", "../examples/calc.y"} {
+		path := path
+		tests = append(tests, struct {
+			name string
+			load func() (*ll1.Grammar, error)
+		}{path, func() (*ll1.Grammar, error) { return importer.LoadFile(path) }})
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.GInit(); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := WriteBNF(&buf, g); err != nil {
+				t.Fatal(err)
+			}
+			read, err := ll1.ParseGrammar(buf.String())
+			if err != nil {
+				t.Fatalf("ParseGrammar:\n%s\n%v", buf.String(), err)
+			}
+			if err := read.Validate(); err != nil {
+				t.Fatalf("Validate:\n%s\n%v", buf.String(), err)
+			}
+			read.MarkTerminals()
+			if got, want := read.String(), g.String(); got != want {
+				t.Errorf("round trip changed the grammar:\n got:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := terminalValues(read), terminalValues(g); got != want {
+				t.Errorf("terminals = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// terminalValues
+// 终结符的值，用Go的字符串字面量写出，能看出反斜杠和控制字符
+func terminalValues(g *ll1.Grammar) string {
+	var values []string
+	for _, s := range g.GetTerminals() {
+		values = append(values, strconv.Quote(s.Value))
+	}
+	return strings.Join(values, " ")
+}
+
+// TestANTLRLiteralTokensFirst
+// 按原文匹配的关键字要定义在正则表达式的记号之前，否则ANTLR会把sqrt识别为ID
+func TestANTLRLiteralTokensFirst(t *testing.T) {
+	g, err := ll1.LoadGrammarFile("../examples/calc.ll1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteANTLR(&buf, g, "Calc"); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	sqrt, id := strings.Index(out, "SQRT :"), strings.Index(out, "ID :")
+	if sqrt < 0 || id < 0 || sqrt > id {
+		t.Errorf("SQRT must be defined before ID:\n%s", out)
+	}
+}
@@ -0,0 +1,270 @@
+// Package export 将初始化后的文法的分析表和各个集合导出为JSON、CSV、Markdown和HTML格式，
+// 将分析过程导出为JSON和CSV格式，
+// 行和列的顺序与ll1包中的顺序一致，导出的结果可以直接比较差异。
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// Tables
+// 文法的nullable、first、follow、select集和预测分析表，Terminals的最后一个是输入结束符End
+type Tables struct {
+	Start        string      `json:"start"`
+	NonTerminals []string    `json:"nonterminals"`
+	Terminals    []string    `json:"terminals"`
+	End          string      `json:"end"`
+	LL1          bool        `json:"ll1"`
+	Sets         []SetRow    `json:"sets"`
+	Select       []SelectRow `json:"select"`
+	Predict      []Entry     `json:"predict"`
+	Conflicts    []string    `json:"conflicts,omitempty"`
+}
+
+// SetRow
+// 一个非终结符的nullable、first和follow集
+type SetRow struct {
+	NonTerminal string   `json:"nonterminal"`
+	Nullable    bool     `json:"nullable"`
+	First       []string `json:"first"`
+	Follow      []string `json:"follow"`
+}
+
+// SelectRow
+// 一个备选项的select集
+type SelectRow struct {
+	Production string   `json:"production"`
+	Select     []string `json:"select"`
+}
+
+// Entry
+// 预测分析表中的一项 M[NonTerminal, Terminal] = Production
+type Entry struct {
+	NonTerminal string `json:"nonterminal"`
+	Terminal    string `json:"terminal"`
+	Production  string `json:"production"`
+}
+
+// NewTables
+// 从GInit之后的文法中收集各个集合和预测分析表，文法不是LL1文法时Predict为空，Conflicts列出冲突；
+// 冲突都被%prefer解决时仍有Predict，Conflicts中的冲突作为警告
+func NewTables(g *ll1.Grammar) *Tables {
+	t := &Tables{
+		Start: g.Start.Value,
+		LL1:   g.Predict != nil,
+	}
+	nonTerminals := g.GetNonTerminals()
+	for _, nt := range nonTerminals {
+		t.NonTerminals = append(t.NonTerminals, nt.Value)
+	}
+	// 预测分析表的列：终结符和结束符
+	columns := append(g.GetTerminals(), g.EndSymbol())
+	for _, term := range columns {
+		t.Terminals = append(t.Terminals, term.Value)
+	}
+	t.End = g.EndSymbol().Value
+
+	for _, nt := range nonTerminals {
+		t.Sets = append(t.Sets, SetRow{
+			NonTerminal: nt.Value,
+			Nullable:    g.Nullable[nt.Value],
+			First:       values(g.FirstSet[nt].Symbols()),
+			Follow:      values(g.FollowSet[nt].Symbols()),
+		})
+	}
+	for _, prod := range g.Productions {
+		for _, alt := range prod.Right {
+			t.Select = append(t.Select, SelectRow{
+				Production: ll1.Production{Left: prod.Left, Right: []ll1.Alternative{alt}}.String(),
+				Select:     values(g.Select(prod.Left, alt.Symbols)),
+			})
+		}
+	}
+	for _, nt := range nonTerminals {
+		for _, term := range columns {
+			if prod, ok := g.Predict[nt][term]; ok {
+				t.Predict = append(t.Predict, Entry{NonTerminal: nt.Value, Terminal: term.Value, Production: prod.String()})
+			}
+		}
+	}
+	for _, c := range g.Conflicts() {
+		t.Conflicts = append(t.Conflicts, c.String())
+	}
+	return t
+}
+
+func values(symbols []ll1.Symbol) []string {
+	result := make([]string, len(symbols))
+	for i, s := range symbols {
+		result[i] = s.Value
+	}
+	return result
+}
+
+// grid
+// 把预测分析表展开为行是非终结符、列是终结符的二维表，第一行是表头，没有产生式的格子为空
+func (t *Tables) grid() [][]string {
+	rows := [][]string{append([]string{""}, t.Terminals...)}
+	for _, nt := range t.NonTerminals {
+		row := make([]string, len(t.Terminals)+1)
+		row[0] = nt
+		for _, e := range t.Predict {
+			if e.NonTerminal != nt {
+				continue
+			}
+			for i, term := range t.Terminals {
+				if term == e.Terminal {
+					row[i+1] = e.Production
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (t *Tables) setRows() [][]string {
+	rows := [][]string{{"nonterminal", "nullable", "first", "follow"}}
+	for _, r := range t.Sets {
+		rows = append(rows, []string{r.NonTerminal, fmt.Sprint(r.Nullable),
+			"{" + strings.Join(r.First, ", ") + "}", "{" + strings.Join(r.Follow, ", ") + "}"})
+	}
+	return rows
+}
+
+// conflictRows
+// 每个冲突一行，没有冲突时返回nil；冲突都被%prefer解决时这些是警告
+func (t *Tables) conflictRows() [][]string {
+	if len(t.Conflicts) == 0 {
+		return nil
+	}
+	rows := [][]string{{"conflict"}}
+	for _, c := range t.Conflicts {
+		rows = append(rows, []string{c})
+	}
+	return rows
+}
+
+func (t *Tables) selectRows() [][]string {
+	rows := [][]string{{"production", "select"}}
+	for _, r := range t.Select {
+		rows = append(rows, []string{r.Production, "{" + strings.Join(r.Select, ", ") + "}"})
+	}
+	return rows
+}
+
+// WriteJSON
+// 以缩进的JSON格式写出
+func (t *Tables) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(t)
+}
+
+// WriteCSV
+// 依次写出预测分析表、nullable/first/follow集和select集三张表，有冲突时最后是冲突表，表之间用空行分隔
+func (t *Tables) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	for i, table := range t.titledTables() {
+		if i > 0 {
+			if err := cw.Write(nil); err != nil {
+				return err
+			}
+		}
+		if err := cw.WriteAll(table.rows); err != nil {
+			return err
+		}
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown
+// 以Markdown表格写出，每张表前有一个标题
+func (t *Tables) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	for i, table := range t.titledTables() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("## " + table.title + "\n\n")
+		for j, row := range table.rows {
+			cells := make([]string, len(row))
+			for k, cell := range row {
+				cells[k] = strings.ReplaceAll(cell, "|", "\\|")
+			}
+			sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+			if j == 0 {
+				sb.WriteString(strings.Repeat("| --- ", len(row)) + "|\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteHTML
+// 以HTML表格写出，每张表前有一个标题，只包含表格本身，不是完整的HTML文档
+func (t *Tables) WriteHTML(w io.Writer) error {
+	var sb strings.Builder
+	for _, table := range t.titledTables() {
+		sb.WriteString("<h2>" + html.EscapeString(table.title) + "</h2>\n<table>\n")
+		for j, row := range table.rows {
+			tag := "td"
+			if j == 0 {
+				tag = "th"
+			}
+			sb.WriteString("  <tr>")
+			for _, cell := range row {
+				sb.WriteString("<" + tag + ">" + html.EscapeString(cell) + "</" + tag + ">")
+			}
+			sb.WriteString("</tr>\n")
+		}
+		sb.WriteString("</table>\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type titledTable struct {
+	title string
+	rows  [][]string
+}
+
+func (t *Tables) titledTables() []titledTable {
+	tables := []titledTable{
+		{"Predict Table", t.grid()},
+		{"Nullable, First and Follow Sets", t.setRows()},
+		{"Select Sets", t.selectRows()},
+	}
+	if rows := t.conflictRows(); rows != nil {
+		tables = append(tables, titledTable{"Conflicts", rows})
+	}
+	return tables
+}
+
+// Formats 支持的导出格式
+var Formats = []string{"json", "csv", "markdown", "html"}
+
+// Write
+// 按format写出，format为Formats之一，md是markdown的简写
+func (t *Tables) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return t.WriteJSON(w)
+	case "csv":
+		return t.WriteCSV(w)
+	case "markdown", "md":
+		return t.WriteMarkdown(w)
+	case "html":
+		return t.WriteHTML(w)
+	}
+	return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// Trace
+// 一次分析的完整过程，可以用于重放分析过程
+type Trace struct {
+	Input    string      `json:"input"`
+	Accepted bool        `json:"accepted"`
+	Steps    []TraceStep `json:"steps"`
+	Errors   []string    `json:"errors,omitempty"`
+}
+
+// TraceStep
+// 分析过程中的一步：执行动作前的分析栈（栈底在前）和剩余输入，执行的动作，
+// 动作为expand时使用的产生式，以及这一步发现的错误
+type TraceStep struct {
+	Number     int          `json:"step"`
+	Stack      []string     `json:"stack"`
+	Input      []TraceToken `json:"input"`
+	Action     string       `json:"action"`
+	Production string       `json:"production,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// TraceToken
+// 剩余输入中的一个单词，Kind是终结符，Text是原文
+type TraceToken struct {
+	Kind   string `json:"kind"`
+	Text   string `json:"text"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// NewTrace
+// 从分析结果中收集每一步，input是分析的原始字符串
+func NewTrace(input string, result ll1.ParseResult) *Trace {
+	t := &Trace{Input: input, Accepted: result.Accepted, Steps: make([]TraceStep, len(result.Steps))}
+	for i, step := range result.Steps {
+		ts := TraceStep{
+			Number: step.Number,
+			Stack:  values(step.AnalysisStack),
+			Input:  make([]TraceToken, len(step.Input)),
+			Action: step.Action.String(),
+		}
+		for j, tok := range step.Input {
+			ts.Input[j] = TraceToken{Kind: tok.Kind, Text: tok.Text, Line: tok.Pos.Line, Column: tok.Pos.Column}
+		}
+		if step.Action == ll1.ActionExpand {
+			ts.Production = step.Production.String()
+		}
+		if step.Error != nil {
+			ts.Error = step.Error.Error()
+		}
+		t.Steps[i] = ts
+	}
+	for _, err := range result.Errors {
+		t.Errors = append(t.Errors, err.Error())
+	}
+	return t
+}
+
+// WriteJSON
+// 以缩进的JSON格式写出
+func (t *Trace) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(t)
+}
+
+// WriteCSV
+// 每一步一行，分析栈和剩余输入中的符号用空格分隔，剩余输入使用单词的原文，末尾是结束符
+func (t *Trace) WriteCSV(w io.Writer) error {
+	rows := [][]string{{"step", "stack", "input", "action", "production", "error"}}
+	for _, step := range t.Steps {
+		input := make([]string, len(step.Input))
+		for i, tok := range step.Input {
+			// 输入末尾的结束符没有原文
+			input[i] = tok.Text
+			if input[i] == "" {
+				input[i] = tok.Kind
+			}
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(step.Number),
+			strings.Join(step.Stack, " "),
+			strings.Join(input, " "),
+			step.Action,
+			step.Production,
+			step.Error,
+		})
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// TraceFormats 分析过程支持的导出格式
+var TraceFormats = []string{"json", "csv"}
+
+// Write
+// 按format写出，format为TraceFormats之一
+func (t *Trace) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return t.WriteJSON(w)
+	case "csv":
+		return t.WriteCSV(w)
+	}
+	return fmt.Errorf("unknown trace format %q, expected one of %s", format, strings.Join(TraceFormats, ", "))
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+func newTrace(t *testing.T, input string) *Trace {
+	t.Helper()
+	g, err := ll1.ParseGrammar("S -> a S | b ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := g.Tokenize(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTrace(input, g.Parse(tokens))
+}
+
+// TestTraceJSON
+// 检查JSON的字段名和值，空的production、error和errors不写出
+func TestTraceJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTrace(t, "a b").WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["input"] != "a b" || got["accepted"] != true {
+		t.Errorf("input = %v, accepted = %v", got["input"], got["accepted"])
+	}
+	if _, ok := got["errors"]; ok {
+		t.Errorf("accepted trace has errors: %v", got["errors"])
+	}
+	steps := got["steps"].([]interface{})
+	if len(steps) != 5 {
+		t.Fatalf("%d steps, want 5", len(steps))
+	}
+	want := map[string]interface{}{
+		"step":  1.0,
+		"stack": []interface{}{"#", "S"},
+		"input": []interface{}{
+			map[string]interface{}{"kind": "a", "text": "a", "line": 1.0, "column": 1.0},
+			map[string]interface{}{"kind": "b", "text": "b", "line": 1.0, "column": 3.0},
+			map[string]interface{}{"kind": "#", "text": "", "line": 1.0, "column": 4.0},
+		},
+		"action":     "expand",
+		"production": "S -> aS",
+	}
+	if !reflect.DeepEqual(steps[0], want) {
+		t.Errorf("step 1 = %v, want %v", steps[0], want)
+	}
+	match := steps[1].(map[string]interface{})
+	if _, ok := match["production"]; ok || match["action"] != "match" {
+		t.Errorf("step 2 = %v, want a match without production", match)
+	}
+	if _, ok := match["error"]; ok {
+		t.Errorf("step 2 has an error: %v", match["error"])
+	}
+
+	buf.Reset()
+	if err := newTrace(t, "a a").WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var rejected Trace
+	if err := json.Unmarshal(buf.Bytes(), &rejected); err != nil {
+		t.Fatal(err)
+	}
+	const msg = "1:4: unexpected end of input, expected a, b"
+	if rejected.Accepted || !reflect.DeepEqual(rejected.Errors, []string{msg}) {
+		t.Errorf("accepted = %v, errors = %q", rejected.Accepted, rejected.Errors)
+	}
+	if step := rejected.Steps[4]; step.Action != "pop" || step.Error != msg {
+		t.Errorf("step 5 = %+v, want pop with %q", step, msg)
+	}
+}
+
+// TestTraceCSV
+// 每一步一行，表头之后依次是步骤、分析栈、剩余输入、动作、产生式和错误
+func TestTraceCSV(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"a b", []string{
+			"step,stack,input,action,production,error",
+			"1,# S,a b #,expand,S -> aS,",
+			"2,# S a,a b #,match,,",
+			"3,# S,b #,expand,S -> b,",
+			"4,# b,b #,match,,",
+			"5,#,#,accept,,",
+		}},
+		{"a a", []string{
+			"step,stack,input,action,production,error",
+			"1,# S,a a #,expand,S -> aS,",
+			"2,# S a,a a #,match,,",
+			"3,# S,a #,expand,S -> aS,",
+			"4,# S a,a #,match,,",
+			`5,# S,#,pop,,"1:4: unexpected end of input, expected a, b"`,
+			"6,#,#,error,,",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := newTrace(t, tt.input).Write(&buf, "csv"); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := buf.String(), strings.Join(tt.want, "\n")+"\n"; got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTraceUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := newTrace(t, "b").Write(&buf, "xml")
+	if err == nil || err.Error() != `unknown trace format "xml", expected one of json, csv` {
+		t.Errorf("Write() = %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/wrilove/Table-drives-LL-1-parser
+
+go 1.21
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 用 go test -run TestGolden -update 重新生成testdata中的golden文件
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenGrammars
+// examples中的文法和用来输出分析过程的输入串，exit是各子命令期望的退出码
+var goldenGrammars = []struct {
+	name  string
+	input string
+	exit  map[string]int
+}{
+	{"calc", "sqrt(x + 2.5) * y  # comment", map[string]int{"sets": exitOK, "table": exitOK, "trace": exitOK}},
+	{"expr", "id + num * ( id - num )", map[string]int{"sets": exitOK, "table": exitOK, "trace": exitOK}},
+	{"ll1", "a a c b b d", map[string]int{"sets": exitOK, "table": exitOK, "trace": exitOK}},
+	{"not_ll1", "c d a b", map[string]int{"sets": exitOK, "table": exitFailed, "trace": exitInvalid}},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tt := range goldenGrammars {
+		grammar := filepath.Join("examples", tt.name+".ll1")
+		input := filepath.Join(t.TempDir(), "input")
+		if err := os.WriteFile(input, []byte(tt.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runs := map[string]func() int{
+			"sets":  func() int { return runSets([]string{grammar}) },
+			"table": func() int { return runTable([]string{grammar}) },
+			"trace": func() int { return runParse([]string{grammar, input}) },
+		}
+		for _, kind := range []string{"sets", "table", "trace"} {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				got, code := captureStdout(t, runs[kind])
+				if code != tt.exit[kind] {
+					t.Errorf("exit code = %d, want %d", code, tt.exit[kind])
+				}
+				path := filepath.Join("testdata", tt.name+"."+kind+".golden")
+				if *update {
+					if err := os.WriteFile(path, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("%v; run go test -run TestGolden -update to create it", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("output differs from %s\ngot:\n%s\nwant:\n%s", path, got, want)
+				}
+			})
+		}
+	}
+}
+
+// captureStdout
+// 运行子命令，返回它写到标准输出的内容和退出码
+func captureStdout(t *testing.T, run func() int) ([]byte, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	code := run()
+	os.Stdout = stdout
+	w.Close()
+	return <-done, code
+}
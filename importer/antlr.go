@@ -0,0 +1,241 @@
+package importer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// ParseANTLR
+// 读取ANTLR 4文法中的语法规则：
+// 小写字母开头的规则是非终结符，大写字母开头的词法规则名是终结符，'...'是终结符，EOF被忽略；
+// ( ) ? * + 按EBNF展开，空备选项为ε；标签、动作、规则参数和返回值、元素选项、catch/finally被忽略；
+// 词法规则不参与分析，只有内容为单个字面量的词法规则（如 PLUS : '+' ;）会绑定到该原文。
+// 语义谓词、~、.、..、import和lexer grammar报告为不支持
+func ParseANTLR(src string) (*ll1.Grammar, error) {
+	tokens, err := scan(src, false)
+	if err != nil {
+		return nil, err
+	}
+	c := &antlrConverter{cursor: cursor{tokens: tokens}, out: newEmitter(), decls: newTokenDecls()}
+	if err := c.convert(); err != nil {
+		return nil, err
+	}
+	c.out.declare(c.decls)
+	return build(c.out.String())
+}
+
+type antlrConverter struct {
+	cursor
+	out   *emitter
+	decls *tokenDecls
+}
+
+// skipTo
+// 跳过记号直到text（包括text）
+func (c *antlrConverter) skipTo(text string) error {
+	for {
+		tok := c.next()
+		switch {
+		case tok.kind == tokEOF:
+			return errorAt(tok, "expected %q before end of file", text)
+		case tok.is(tokPunct, text):
+			return nil
+		}
+	}
+}
+
+func isRuleName(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsLower(r)
+}
+
+// convert
+// 逐条处理文法头、选项和规则
+func (c *antlrConverter) convert() error {
+	for {
+		tok := c.peek()
+		switch {
+		case tok.kind == tokEOF:
+			return nil
+		case tok.is(tokIdent, "lexer") && c.peekAt(1).is(tokIdent, "grammar"):
+			return errorAt(tok, "lexer grammar has no parser rules")
+		case tok.is(tokIdent, "parser") || tok.is(tokIdent, "grammar"):
+			if err := c.skipTo(";"); err != nil {
+				return err
+			}
+		case tok.is(tokIdent, "import"):
+			return errorAt(tok, "import of other grammars is not supported, merge them into one file")
+		case (tok.is(tokIdent, "options") || tok.is(tokIdent, "channels")) && c.peekAt(1).kind == tokAction:
+			c.pos += 2
+		case tok.is(tokIdent, "tokens") && c.peekAt(1).kind == tokAction:
+			c.pos++
+			body := c.next().text
+			for _, name := range strings.FieldsFunc(body[1:len(body)-1], func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+				c.decls.add(name)
+			}
+		case tok.is(tokPunct, "@"):
+			// @header {...}、@parser::members {...}
+			for c.peek().kind != tokAction && c.peek().kind != tokEOF {
+				c.next()
+			}
+			c.next()
+		case tok.is(tokIdent, "mode"):
+			if err := c.skipTo(";"); err != nil {
+				return err
+			}
+		case tok.kind == tokIdent:
+			if err := c.rule(); err != nil {
+				return err
+			}
+		default:
+			return errorAt(tok, "unexpected %q, expected a rule", tok.text)
+		}
+	}
+}
+
+// rule
+// 语法规则翻译为 name -> ... ; ，词法规则跳过
+func (c *antlrConverter) rule() error {
+	for _, modifier := range []string{"public", "private", "protected"} {
+		if c.peek().is(tokIdent, modifier) {
+			c.next()
+		}
+	}
+	if c.peek().is(tokIdent, "fragment") {
+		c.next()
+		return c.skipTo(";")
+	}
+	name := c.next()
+	if name.kind != tokIdent {
+		return errorAt(name, "expected rule name, found %q", name.text)
+	}
+	if !isRuleName(name.text) {
+		return c.lexerRule(name)
+	}
+	// 规则参数、returns、locals、throws、options和@init等动作
+	for !c.peek().is(tokPunct, ":") {
+		tok := c.next()
+		if tok.kind == tokEOF {
+			return errorAt(name, "expected ':' after rule %s", name.text)
+		}
+	}
+	c.out.emit(name, name.text)
+	c.out.emit(c.next(), "->")
+	if err := c.alternatives(); err != nil {
+		return err
+	}
+	c.out.emit(c.next(), ";")
+	// 异常处理
+	for c.peek().is(tokIdent, "catch") || c.peek().is(tokIdent, "finally") {
+		c.next()
+		for c.peek().kind == tokBracket {
+			c.next()
+		}
+		if c.peek().kind == tokAction {
+			c.next()
+		}
+	}
+	return nil
+}
+
+// lexerRule
+// 跳过词法规则，内容只有一个字面量时绑定该原文
+func (c *antlrConverter) lexerRule(name token) error {
+	if !c.next().is(tokPunct, ":") {
+		return errorAt(name, "expected ':' after rule %s", name.text)
+	}
+	if lit := c.peek(); lit.kind == tokLiteral && c.peekAt(1).is(tokPunct, ";") {
+		c.decls.literals[name.text] = lit.text
+	}
+	return c.skipTo(";")
+}
+
+// alternatives
+// 翻译到规则末尾的 ; 为止，不消耗 ;
+func (c *antlrConverter) alternatives() error {
+	// empty 当前备选项中还没有写出任何符号
+	empty := true
+	depth := 0
+	for {
+		tok := c.peek()
+		switch {
+		case tok.kind == tokEOF:
+			return errorAt(tok, "expected ';' at the end of the rule")
+		case tok.is(tokPunct, ";") && depth == 0:
+			if empty {
+				c.out.after("ε")
+			}
+			return nil
+		case tok.is(tokPunct, "|") || tok.is(tokPunct, ")"):
+			if empty {
+				c.out.after("ε")
+			}
+			if tok.text == ")" {
+				if depth == 0 {
+					return errorAt(tok, "unmatched ')'")
+				}
+				depth--
+			}
+			c.out.emit(c.next(), tok.text)
+			empty = tok.text == "|"
+		case tok.is(tokPunct, "("):
+			depth++
+			c.out.emit(c.next(), "(")
+			empty = true
+		case tok.is(tokPunct, "?") || tok.is(tokPunct, "*") || tok.is(tokPunct, "+"):
+			c.out.emit(c.next(), tok.text)
+			// 非贪婪的 ?? *? +? 对LL(1)分析没有影响
+			if next := c.peek(); next.is(tokPunct, "?") && next.line == tok.line && next.column == tok.column+1 {
+				c.next()
+			}
+		case tok.is(tokPunct, "#"):
+			// 备选项标签
+			c.next()
+			c.next()
+		case tok.kind == tokAction:
+			c.next()
+			if c.peek().is(tokPunct, "?") {
+				return errorAt(tok, "semantic predicates are not supported")
+			}
+		case tok.kind == tokTag:
+			c.next()
+		case tok.kind == tokIdent && (c.peekAt(1).is(tokPunct, "=") || c.peekAt(1).is(tokPunct, "+=")):
+			// 元素标签 x=expr、xs+=expr
+			c.pos += 2
+		case tok.kind == tokIdent:
+			c.next()
+			switch {
+			case tok.text == "EOF":
+			case isRuleName(tok.text):
+				c.out.emit(tok, "<"+tok.text+">")
+				empty = false
+			default:
+				c.decls.add(tok.text)
+				c.out.emit(tok, tok.text)
+				empty = false
+			}
+			if c.peek().kind == tokBracket {
+				// 规则调用的参数
+				c.next()
+			}
+		case tok.kind == tokLiteral:
+			c.next()
+			if c.peek().is(tokPunct, "..") {
+				return errorAt(tok, "character ranges are not supported in parser rules")
+			}
+			c.out.terminal(tok, tok.text)
+			empty = false
+		case tok.is(tokPunct, "~"):
+			return errorAt(tok, "set complement ~ is not supported")
+		case tok.is(tokPunct, "."):
+			return errorAt(tok, "wildcard . is not supported")
+		case tok.is(tokPunct, "->"):
+			return errorAt(tok, "lexer commands are not allowed in parser rules")
+		default:
+			return errorAt(tok, "unsupported %q in parser rule", tok.text)
+		}
+	}
+}
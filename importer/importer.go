@@ -0,0 +1,510 @@
+// Package importer 读取ANTLR的.g4文件和yacc/bison的.y文件中的语法规则，转换为ll1包的文法。
+//
+// 规则被逐个记号地翻译为ll1的文法记法（EBNF运算符原样保留），每个记号写在原文件中的同一行同一列，
+// 所以ll1.ParseGrammar报告的行号和列号就是原文件中的位置。动作、语义值类型、优先级声明等与
+// LL(1)分析无关的内容被忽略，会改变语言的结构（语义谓词、通配符、yacc的error等）则报告为不支持。
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// LoadFile
+// 按扩展名读取文法文件：.g4为ANTLR文法，.y和.yy为yacc文法，其余按ll1的文法文件格式读取。
+// 错误信息中带有文件名
+func LoadFile(path string) (*ll1.Grammar, error) {
+	var parse func(string) (*ll1.Grammar, error)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".g4":
+		parse = ParseANTLR
+	case ".y", ".yy":
+		parse = ParseYacc
+	default:
+		return ll1.LoadGrammarFile(path)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	g, err := parse(string(src))
+	var syntaxErr *ll1.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		syntaxErr.File = path
+	}
+	return g, err
+}
+
+type tokenKind int
+
+const (
+	tokIdent     tokenKind = iota
+	tokNumber              // yacc中%token后面的编号
+	tokLiteral             // '...' 或 "..."，text是引号中的原文
+	tokPunct               // 标点，包括 %% :: += .. ->
+	tokAction              // { ... }，包括括号
+	tokBracket             // [ ... ]，规则参数、yacc的命名引用或ANTLR的字符集
+	tokTag                 // < ... >，yacc的类型标签或ANTLR的元素选项
+	tokDirective           // yacc的%name，text不含%
+	tokCode                // yacc的 %{ ... %}
+	tokEOF
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+// cursor
+// 两种转换共用的记号游标，tokens以tokEOF结尾，读到末尾后一直停在tokEOF上
+type cursor struct {
+	tokens []token
+	pos    int
+}
+
+func (c *cursor) peek() token {
+	return c.tokens[c.pos]
+}
+
+// peekAt
+// 向后看第n个记号，越过末尾时返回tokEOF
+func (c *cursor) peekAt(n int) token {
+	if c.pos+n < len(c.tokens) {
+		return c.tokens[c.pos+n]
+	}
+	return c.tokens[len(c.tokens)-1]
+}
+
+func (c *cursor) next() token {
+	tok := c.tokens[c.pos]
+	if tok.kind != tokEOF {
+		c.pos++
+	}
+	return tok
+}
+
+func errorAt(tok token, format string, args ...interface{}) error {
+	return &ll1.SyntaxError{Line: tok.line, Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// scanner
+// ANTLR和yacc共用的记号切分，跳过空白和 // /* */ 注释
+type scanner struct {
+	src    string
+	line   int
+	column int
+	yacc   bool
+}
+
+func scan(src string, yacc bool) ([]token, error) {
+	s := &scanner{src: src, line: 1, column: 1, yacc: yacc}
+	var tokens []token
+	for {
+		tok, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// advance
+// 消耗n个字节，更新行号和列号
+func (s *scanner) advance(n int) string {
+	text := s.src[:n]
+	for _, r := range text {
+		if r == '\n' {
+			s.line++
+			s.column = 1
+		} else {
+			s.column++
+		}
+	}
+	s.src = s.src[n:]
+	return text
+}
+
+func (s *scanner) next() (token, error) {
+	for {
+		r, size := utf8.DecodeRuneInString(s.src)
+		switch {
+		case s.src == "":
+			return token{tokEOF, "", s.line, s.column}, nil
+		case unicode.IsSpace(r):
+			s.advance(size)
+			continue
+		case strings.HasPrefix(s.src, "//"):
+			end := strings.IndexByte(s.src, '\n')
+			if end < 0 {
+				end = len(s.src)
+			}
+			s.advance(end)
+			continue
+		case strings.HasPrefix(s.src, "/*"):
+			end := strings.Index(s.src, "*/")
+			if end < 0 {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "unterminated comment"}
+			}
+			s.advance(end + 2)
+			continue
+		}
+		tok := token{line: s.line, column: s.column}
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+			n := 0
+			for n < len(s.src) {
+				c, w := utf8.DecodeRuneInString(s.src[n:])
+				if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) && !(s.yacc && (c == '.' || c == '-') && n > 0) {
+					break
+				}
+				n += w
+			}
+			tok.kind, tok.text = tokIdent, s.advance(n)
+		case unicode.IsDigit(r):
+			n := 0
+			for n < len(s.src) && s.src[n] >= '0' && s.src[n] <= '9' {
+				n++
+			}
+			tok.kind, tok.text = tokNumber, s.advance(n)
+		case r == '\'' || r == '"':
+			n, ok := quotedLength(s.src)
+			if !ok {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "unterminated literal"}
+			}
+			text, err := unescape(s.src[1 : n-1])
+			if err != "" {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: err}
+			}
+			s.advance(n)
+			tok.kind, tok.text = tokLiteral, text
+		case r == '{':
+			n, ok := balancedLength(s.src, '{', '}')
+			if !ok {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "unterminated action, missing '}'"}
+			}
+			tok.kind, tok.text = tokAction, s.advance(n)
+		case r == '[':
+			n, ok := balancedLength(s.src, '[', ']')
+			if !ok {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "missing ']'"}
+			}
+			tok.kind, tok.text = tokBracket, s.advance(n)
+		case r == '<' && strings.IndexByte(s.src, '>') > 0 && !strings.Contains(s.src[:strings.IndexByte(s.src, '>')], "\n"):
+			tok.kind, tok.text = tokTag, s.advance(strings.IndexByte(s.src, '>')+1)
+		case s.yacc && strings.HasPrefix(s.src, "%{"):
+			end := strings.Index(s.src, "%}")
+			if end < 0 {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "unterminated %{ code block"}
+			}
+			tok.kind, tok.text = tokCode, s.advance(end+2)
+		case s.yacc && strings.HasPrefix(s.src, "%%"):
+			tok.kind, tok.text = tokPunct, s.advance(2)
+		case s.yacc && r == '%':
+			n := 1
+			for n < len(s.src) && (s.src[n] == '_' || s.src[n] == '-' || unicode.IsLetter(rune(s.src[n]))) {
+				n++
+			}
+			if n == 1 {
+				return token{}, &ll1.SyntaxError{Line: s.line, Column: s.column, Msg: "expected directive name after '%'"}
+			}
+			tok.kind, tok.text = tokDirective, s.advance(n)[1:]
+		default:
+			n := size
+			for _, p := range []string{"::", "+=", "..", "->"} {
+				if strings.HasPrefix(s.src, p) {
+					n = len(p)
+				}
+			}
+			tok.kind, tok.text = tokPunct, s.advance(n)
+		}
+		return tok, nil
+	}
+}
+
+// quotedLength
+// 以引号开头的字面量的字节长度，包括两端的引号，支持反斜杠转义
+func quotedLength(src string) (int, bool) {
+	quote := src[0]
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, true
+		case '\n':
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// balancedLength
+// 以open开头、到匹配的close为止的字节长度，跳过其中的字面量、注释和转义字符
+func balancedLength(src string, open, close byte) (int, bool) {
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '\\':
+			i++
+		case (c == '\'' || c == '"') && open == '{':
+			n, ok := quotedLength(src[i:])
+			if !ok {
+				// C代码中单独的引号，例如字符常量之外的撇号，按普通字符处理
+				continue
+			}
+			i += n - 1
+		case strings.HasPrefix(src[i:], "//") && open == '{':
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return 0, false
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*") && open == '{':
+			end := strings.Index(src[i:], "*/")
+			if end < 0 {
+				return 0, false
+			}
+			i += end + 1
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// emitter
+// 按原位置写出ll1记法的记号：换行补齐到原来的行，空格补齐到原来的列，
+// 写出的记号比原记号长时后面的记号只用一个空格隔开
+type emitter struct {
+	sb     strings.Builder
+	line   int
+	column int
+	// spaces 写出的引号终结符中出现的空白字符
+	spaces string
+}
+
+func newEmitter() *emitter {
+	return &emitter{line: 1, column: 1}
+}
+
+func (e *emitter) emit(at token, text string) {
+	for e.line < at.line {
+		e.sb.WriteByte('\n')
+		e.line++
+		e.column = 1
+	}
+	switch {
+	case e.column < at.column:
+		e.sb.WriteString(strings.Repeat(" ", at.column-e.column))
+		e.column = at.column
+	case e.column > 1:
+		e.sb.WriteByte(' ')
+		e.column++
+	}
+	e.sb.WriteString(text)
+	e.column += utf8.RuneCountInString(text)
+}
+
+// terminal
+// 在at的位置写出引号括起来的终结符text
+func (e *emitter) terminal(at token, text string) {
+	e.noteSpaces(text)
+	e.emit(at, ll1.QuoteTerminal(text, '\''))
+}
+
+// noteSpaces
+// 记录终结符中的空白字符，见declare
+func (e *emitter) noteSpaces(text string) {
+	for _, r := range text {
+		if unicode.IsSpace(r) && !strings.ContainsRune(e.spaces, r) {
+			e.spaces += string(r)
+		}
+	}
+}
+
+// declare
+// 写出终结符的%token声明。终结符中有空白字符（例如yacc的'\n'）时，
+// 默认跳过所有空白的词法规则会吞掉它们，再写出一条只跳过其余空白的%skip
+func (e *emitter) declare(decls *tokenDecls) {
+	for _, name := range decls.names {
+		if lit, ok := decls.literals[name]; ok {
+			e.noteSpaces(lit)
+		}
+	}
+	for _, d := range decls.directives() {
+		e.directive(d)
+	}
+	if e.spaces == "" {
+		return
+	}
+	var class strings.Builder
+	for _, r := range " \t\n\r\f\v" {
+		if !strings.ContainsRune(e.spaces, r) {
+			class.WriteString(strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`, "\f", `\f`, "\v", `\v`).Replace(string(r)))
+		}
+	}
+	if class.Len() > 0 {
+		e.directive("%skip /[" + class.String() + "]+/")
+	}
+}
+
+// after
+// 紧跟在上一个写出的记号之后写出text，用于原文件中没有对应记号的ε
+func (e *emitter) after(text string) {
+	e.sb.WriteByte(' ')
+	e.sb.WriteString(text)
+	e.column += 1 + utf8.RuneCountInString(text)
+}
+
+// directive
+// 在所有规则之后另起一行写出指令
+func (e *emitter) directive(text string) {
+	e.sb.WriteByte('\n')
+	e.sb.WriteString(text)
+	e.line++
+	e.column = 1 + utf8.RuneCountInString(text)
+}
+
+func (e *emitter) String() string {
+	return e.sb.String()
+}
+
+// unescape
+// 把字面量引号中的原文翻译为它表示的字符：C的 \n \t \r \0 \a \b \f \v \\ \' \" \?、
+// 八进制 \ooo、十六进制 \xhh 和ANTLR的 \uXXXX、\u{X...}。其他转义返回错误信息，不猜测它的含义
+func unescape(raw string) (string, string) {
+	var sb strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			sb.WriteByte(raw[i])
+			continue
+		}
+		i++
+		switch c := raw[i]; {
+		case strings.IndexByte(`\'"?`, c) >= 0:
+			sb.WriteByte(c)
+		case strings.IndexByte("abfnrtv", c) >= 0:
+			sb.WriteByte("\a\b\f\n\r\t\v"[strings.IndexByte("abfnrtv", c)])
+		case c >= '0' && c <= '7':
+			n, v := 0, 0
+			for ; n < 3 && i+n < len(raw) && raw[i+n] >= '0' && raw[i+n] <= '7'; n++ {
+				v = v*8 + int(raw[i+n]-'0')
+			}
+			if v > 0xff {
+				return "", fmt.Sprintf("octal escape \\%s is out of range", raw[i:i+n])
+			}
+			sb.WriteByte(byte(v))
+			i += n - 1
+		case c == 'x' || c == 'u':
+			digits := raw[i+1:]
+			braced := c == 'u' && strings.HasPrefix(digits, "{")
+			if braced {
+				end := strings.IndexByte(digits, '}')
+				if end < 0 {
+					return "", `unterminated \u{ escape`
+				}
+				digits = digits[1:end]
+			} else if c == 'u' {
+				digits = digits[:min(4, len(digits))]
+			} else {
+				digits = digits[:len(digits)-len(strings.TrimLeft(digits, "0123456789abcdefABCDEF"))]
+			}
+			v, err := strconv.ParseUint(digits, 16, 32)
+			if err != nil || c == 'u' && !braced && len(digits) != 4 || !utf8.ValidRune(rune(v)) {
+				return "", fmt.Sprintf("invalid escape \\%c%s", c, digits)
+			}
+			if c == 'x' {
+				if v > 0xff {
+					return "", fmt.Sprintf("hexadecimal escape \\x%s is out of range", digits)
+				}
+				sb.WriteByte(byte(v))
+			} else {
+				sb.WriteRune(rune(v))
+			}
+			i += len(digits)
+			if braced {
+				i += 2
+			}
+		default:
+			r, _ := utf8.DecodeRuneInString(raw[i:])
+			return "", fmt.Sprintf("unsupported escape \\%c in literal", r)
+		}
+	}
+	return sb.String(), ""
+}
+
+// tokenDecls
+// 按出现的顺序记录需要用%token声明的终结符以及绑定的原文
+type tokenDecls struct {
+	names    []string
+	literals map[string]string
+	seen     map[string]bool
+}
+
+func newTokenDecls() *tokenDecls {
+	return &tokenDecls{literals: make(map[string]string), seen: make(map[string]bool)}
+}
+
+func (d *tokenDecls) add(name string) {
+	if !d.seen[name] {
+		d.seen[name] = true
+		d.names = append(d.names, name)
+	}
+}
+
+// directives
+// 每个终结符一条%token，绑定了原文的写成 %token NAME "text"
+func (d *tokenDecls) directives() []string {
+	var result []string
+	for _, name := range d.names {
+		if lit, ok := d.literals[name]; ok {
+			result = append(result, "%token "+name+" "+ll1.QuoteTerminal(lit, '"'))
+		} else {
+			result = append(result, "%token "+name)
+		}
+	}
+	return result
+}
+
+// build
+// 解析翻译得到的ll1记法，并把左部相同的产生式合并为一条
+func build(src string) (*ll1.Grammar, error) {
+	g, err := ll1.ParseGrammar(src)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[ll1.Symbol]int)
+	var prods []ll1.Production
+	for _, prod := range g.Productions {
+		if i, ok := index[prod.Left]; ok {
+			prods[i].Right = append(prods[i].Right, prod.Right...)
+			continue
+		}
+		index[prod.Left] = len(prods)
+		prods = append(prods, prod)
+	}
+	g.Productions = prods
+	return g, nil
+}
@@ -0,0 +1,264 @@
+package importer
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// importCase
+// 导入一个文法，want是合并、展开EBNF之后的产生式，terminals是期望被声明为终结符的名字
+type importCase struct {
+	name      string
+	src       string
+	want      string
+	start     string
+	terminals []string
+	bindings  map[string]string
+}
+
+func checkImport(t *testing.T, parse func(string) (*ll1.Grammar, error), tt importCase) {
+	t.Helper()
+	g, err := parse(tt.src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := render(g); got != tt.want {
+		t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+	}
+	if g.Start.Value != tt.start {
+		t.Errorf("start = %s, want %s", g.Start.Value, tt.start)
+	}
+	for _, name := range tt.terminals {
+		if terminal, ok := g.Declared[name]; !ok || !terminal {
+			t.Errorf("%s is not declared as a terminal", name)
+		}
+	}
+	for name, want := range tt.bindings {
+		if g.Lexer == nil {
+			t.Fatalf("no lexer, want %s bound to %q", name, want)
+		}
+		if literal, _, ok := g.Lexer.Binding(name); !ok || literal != want {
+			t.Errorf("%s is bound to %q, want %q", name, literal, want)
+		}
+	}
+}
+
+// render
+// 每行一条产生式，符号之间用空格分隔，含有空白、反斜杠或控制字符的符号写成Go的字符串字面量
+func render(g *ll1.Grammar) string {
+	var lines []string
+	for _, prod := range g.Productions {
+		var alts []string
+		for _, alt := range prod.Right {
+			var symbols []string
+			for _, s := range alt.Symbols {
+				if strings.IndexFunc(s.Value, func(r rune) bool { return r <= ' ' || r == '\\' }) >= 0 {
+					symbols = append(symbols, strconv.Quote(s.Value))
+				} else {
+					symbols = append(symbols, s.Value)
+				}
+			}
+			alts = append(alts, strings.Join(symbols, " "))
+		}
+		lines = append(lines, prod.Left.Value+" -> "+strings.Join(alts, " | "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkSyntaxError
+// 错误应当是带有原文件中位置的ll1.SyntaxError，信息中含有msg
+func checkSyntaxError(t *testing.T, err error, line, column int, msg string) {
+	t.Helper()
+	var syntaxErr *ll1.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("error = %v, want a SyntaxError", err)
+	}
+	if syntaxErr.Line != line || syntaxErr.Column != column || !strings.Contains(syntaxErr.Msg, msg) {
+		t.Errorf("error = %v, want %d:%d: ...%s...", err, line, column, msg)
+	}
+}
+
+func TestParseANTLR(t *testing.T) {
+	tests := []importCase{
+		{
+			name: "labels and actions",
+			src: `grammar T;
+options { language = Go; }
+@header { import "fmt" }
+s : x=e ';'          # exprStat
+  | ids+=ID '=' e    { fmt.Println($ids) } # assign
+  ;
+e[int n] returns [int v] : ID args? { $v = 1; } ;
+args : '(' ')' ;
+ID : [a-z]+ ;`,
+			want:      "s -> e ; | ID = e\ne -> ID e_opt1\ne_opt1 -> args | ε\nargs -> ( )",
+			start:     "s",
+			terminals: []string{"ID"},
+		},
+		{
+			name: "tokens and literal bindings",
+			src: `parser grammar T;
+tokens { IF, ELSE }
+s : IF s (ELSE s)? | PLUS | EOF ;
+PLUS : '+' ;
+fragment DIGIT : [0-9] ;`,
+			want:      "s -> IF s s_opt1 | PLUS | ε\ns_opt1 -> ELSE s | ε",
+			start:     "s",
+			terminals: []string{"IF", "ELSE", "PLUS"},
+			bindings:  map[string]string{"PLUS": "+"},
+		},
+		{
+			name:  "repetition and empty alternative",
+			src:   "grammar T;\nlist : item+ | ;\nitem : 'a' ('b' | 'c')* ;",
+			want:  "list -> item list_rep1 | ε\nlist_rep1 -> item list_rep1 | ε\nitem -> a item_rep1\nitem_rep1 -> b item_rep1 | c item_rep1 | ε",
+			start: "list",
+		},
+		{
+			name:  "escapes",
+			src:   "grammar T;\ns : 'a' '\\n' | '\\u0042' '\\\\' ;",
+			want:  `s -> a "\n" | B "\\"`,
+			start: "s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkImport(t, ParseANTLR, tt)
+		})
+	}
+}
+
+func TestParseANTLRErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		src          string
+		line, column int
+		msg          string
+	}{
+		{"predicate", "grammar T;\ns : {ok()}? 'a' ;", 2, 5, "semantic predicates"},
+		{"complement", "grammar T;\ns : ~'a' ;", 2, 5, "set complement"},
+		{"wildcard", "grammar T;\ns :\n  'a' . ;", 3, 7, "wildcard"},
+		{"range", "grammar T;\ns : 'a'..'z' ;", 2, 5, "character ranges"},
+		{"import", "grammar T;\nimport U;", 2, 1, "import"},
+		{"lexer grammar", "lexer grammar T;", 1, 1, "lexer grammar"},
+		{"unknown escape", "grammar T;\ns : 'a' '\\q' ;", 2, 9, "unsupported escape \\q"},
+		{"unterminated literal", "grammar T;\ns : 'a ;", 2, 5, "unterminated literal"},
+		// ll1.ParseGrammar的错误也是原文件中的位置
+		{"epsilon literal", "grammar T;\ns :   'a'\n    | 'ε' ;", 3, 7, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseANTLR(tt.src)
+			checkSyntaxError(t, err, tt.line, tt.column, tt.msg)
+		})
+	}
+}
+
+func TestParseYacc(t *testing.T) {
+	tests := []importCase{
+		{
+			name: "declarations, actions and merging",
+			src: `%{
+#include <stdio.h>
+%}
+%union { int n; }
+%token <n> NUM 300
+%token PLUS "+" ID "identifier"
+%left PLUS
+%start e
+%%
+e : e "+" t   { $$ = $1 + $3; }
+  | t ;
+t : NUM
+  | %empty
+  ;
+e : '(' e[inner] ')' %prec PLUS ;
+%%
+int main(void) { return yyparse(); }`,
+			want:      "e -> e PLUS t | t | ( e )\nt -> NUM | ε",
+			start:     "e",
+			terminals: []string{"NUM", "PLUS", "ID"},
+			bindings:  map[string]string{"PLUS": "+"},
+		},
+		{
+			name:      "rules without semicolons",
+			src:       "%token a\n%%\ns : a s\n  |\nr : s a",
+			want:      "s -> a s | ε\nr -> s a",
+			start:     "s",
+			terminals: []string{"a"},
+		},
+		{
+			name:      "identifiers with . and -",
+			src:       "%token left-paren right.paren left_paren\n%%\nexpr.list : left-paren expr.list right.paren | left_paren ;",
+			want:      "expr_list -> left_paren_ expr_list right_paren | left_paren",
+			start:     "expr_list",
+			terminals: []string{"left_paren_", "right_paren", "left_paren"},
+		},
+		{
+			name:  "escapes",
+			src:   "%%\nline : '\\n' | 'a' '\\t' | '\\x41' '\\101' | '\\\\' ;",
+			want:  `line -> "\n" | a "\t" | A A | "\\"`,
+			start: "line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkImport(t, ParseYacc, tt)
+		})
+	}
+}
+
+// TestParseYaccNewlineToken
+// '\n'是真正的换行符，导入的文法只跳过其余的空白，换行可以作为单词
+func TestParseYaccNewlineToken(t *testing.T) {
+	g, err := LoadFile(filepath.Join("..", "examples", "calc.y"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := g.GInit(); err != nil || !ok {
+		t.Fatalf("GInit() = %v, %v", ok, err)
+	}
+	tokens, err := g.Tokenize("LET ID = NUM + NUM\nNUM * ( NUM - NUM )\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, tok := range tokens {
+		kinds = append(kinds, tok.Kind)
+	}
+	if got := strings.Join(kinds, " "); got != "LET ID ASSIGN NUM + NUM \n NUM * ( NUM - NUM ) \n" {
+		t.Errorf("tokens = %q", got)
+	}
+	if result := g.Parse(tokens); !result.Accepted {
+		t.Errorf("Parse rejected the input: %v", result.Errors)
+	}
+}
+
+func TestParseYaccErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		src          string
+		line, column int
+		msg          string
+	}{
+		{"missing %%", "%token a\n", 2, 1, "missing %%"},
+		{"missing colon", "%%\ns a ;", 2, 3, "expected ':'"},
+		{"error token", "%%\ns : a\n  | error ';' ;", 3, 5, "error token"},
+		{"dprec", "%%\ns : a %dprec 1 ;", 2, 7, "%dprec"},
+		{"unknown directive", "%%\ns : a %foo ;", 2, 7, "%foo"},
+		{"empty literal", "%%\ns : '' ;", 2, 5, "empty literal"},
+		{"unknown escape", "%%\ns : '\\q' ;", 2, 5, "unsupported escape \\q"},
+		{"unterminated action", "%%\ns : a { x ;", 2, 7, "unterminated action"},
+		{"epsilon literal", "%%\ns : a\n  | 'ε' ;", 3, 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYacc(tt.src)
+			checkSyntaxError(t, err, tt.line, tt.column, tt.msg)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+// ParseYacc
+// 读取yacc/bison文法中的语法规则：
+// 用%token、%left、%right、%nonassoc、%precedence声明的名字和'...'、"..."是终结符，其余名字是非终结符；
+// %start指定开始符，%empty和空备选项为ε；动作、%{ %}代码、类型标签、%prec、命名引用以及其他声明被忽略，
+// 第二个%%之后的代码不读取。同一个非终结符的多条规则合并为一条产生式。
+// 名字中的.和-换成_，例如left-paren写成left_paren。
+// yacc的error记号和GLR的%dprec、%merge报告为不支持
+func ParseYacc(src string) (*ll1.Grammar, error) {
+	tokens, err := scan(src, true)
+	if err != nil {
+		return nil, err
+	}
+	c := &yaccConverter{cursor: cursor{tokens: tokens}, out: newEmitter(), decls: newTokenDecls(), aliases: make(map[string]string)}
+	if err := c.declarations(); err != nil {
+		return nil, err
+	}
+	if err := c.rules(); err != nil {
+		return nil, err
+	}
+	c.out.declare(c.decls)
+	if c.start != "" {
+		c.out.directive("%start " + c.start)
+	}
+	return build(c.out.String())
+}
+
+type yaccConverter struct {
+	cursor
+	out   *emitter
+	decls *tokenDecls
+	start string
+	// aliases bison别名到记号名的映射
+	aliases map[string]string
+	// idents 含有.或-的名字在ll1记法中的写法，见ident
+	idents map[string]string
+}
+
+// ident
+// yacc的名字可以含有.和-（如left-paren），ll1的记法不能表示，把它们换成_；
+// 换后与文件中的其他名字重名时再加_，同一个名字总是得到同样的结果
+func (c *yaccConverter) ident(name string) string {
+	if !strings.ContainsAny(name, ".-") {
+		return name
+	}
+	if mangled, ok := c.idents[name]; ok {
+		return mangled
+	}
+	if c.idents == nil {
+		c.idents = make(map[string]string)
+	}
+	used := make(map[string]bool)
+	for _, tok := range c.tokens {
+		if tok.kind == tokIdent {
+			used[tok.text] = true
+		}
+	}
+	for _, mangled := range c.idents {
+		used[mangled] = true
+	}
+	mangled := strings.NewReplacer(".", "_", "-", "_").Replace(name)
+	for used[mangled] {
+		mangled += "_"
+	}
+	c.idents[name] = mangled
+	return mangled
+}
+
+// declarations
+// 读取第一个%%之前的声明
+func (c *yaccConverter) declarations() error {
+	for {
+		tok := c.next()
+		switch {
+		case tok.kind == tokEOF:
+			return errorAt(tok, "missing %%%% before the rules")
+		case tok.is(tokPunct, "%%"):
+			return nil
+		case tok.kind == tokDirective:
+			switch tok.text {
+			case "token", "left", "right", "nonassoc", "precedence":
+				c.tokenNames()
+			case "start":
+				name := c.next()
+				if name.kind != tokIdent {
+					return errorAt(name, "expected nonterminal after %%start, found %q", name.text)
+				}
+				c.start = c.ident(name.text)
+			}
+			// 其余声明的参数在下一次循环中作为普通记号跳过
+		}
+	}
+}
+
+// tokenNames
+// %token等声明中的名字，跳过类型标签、编号和别名
+func (c *yaccConverter) tokenNames() {
+	for {
+		switch tok := c.peek(); tok.kind {
+		case tokIdent:
+			c.next()
+			c.decls.add(c.ident(tok.text))
+			if lit := c.peek(); lit.kind == tokLiteral && lit.text != "" {
+				// bison的别名 %token PLUS "+"，规则中的"+"指的是PLUS；
+				// 只有不含字母和数字的别名才是记号的原文，例如"number"只是用于错误信息的名字
+				c.next()
+				c.aliases[lit.text] = c.ident(tok.text)
+				if !strings.ContainsFunc(lit.text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+					c.decls.literals[c.ident(tok.text)] = lit.text
+				}
+			}
+		case tokTag, tokNumber, tokLiteral:
+			c.next()
+		default:
+			return
+		}
+	}
+}
+
+// rules
+// 翻译两个%%之间的规则
+func (c *yaccConverter) rules() error {
+	for {
+		tok := c.peek()
+		switch {
+		case tok.kind == tokEOF || tok.is(tokPunct, "%%"):
+			return nil
+		case tok.is(tokPunct, ";"):
+			c.next()
+		case tok.kind == tokIdent:
+			if err := c.rule(); err != nil {
+				return err
+			}
+		default:
+			return errorAt(tok, "unexpected %q, expected a rule", tok.text)
+		}
+	}
+}
+
+// startsRule
+// 当前记号是否为下一条规则的左部，即后面跟着 : （中间可以有bison的[别名]）
+func (c *yaccConverter) startsRule() bool {
+	if c.peek().kind != tokIdent {
+		return false
+	}
+	next := c.peekAt(1)
+	if next.kind == tokBracket {
+		next = c.peekAt(2)
+	}
+	return next.is(tokPunct, ":")
+}
+
+func (c *yaccConverter) rule() error {
+	name := c.next()
+	if c.peek().kind == tokBracket {
+		c.next()
+	}
+	colon := c.next()
+	if !colon.is(tokPunct, ":") {
+		return errorAt(colon, "expected ':' after rule %s, found %q", name.text, colon.text)
+	}
+	c.out.emit(name, c.ident(name.text))
+	c.out.emit(colon, "->")
+	empty := true
+	for {
+		tok := c.peek()
+		switch {
+		case tok.kind == tokEOF || tok.is(tokPunct, "%%") || tok.is(tokPunct, ";") || c.startsRule():
+			if empty {
+				c.out.after("ε")
+			}
+			// 没有 ; 时下一条规则的 -> 也会结束这条产生式
+			if tok.is(tokPunct, ";") {
+				c.out.emit(c.next(), ";")
+			}
+			return nil
+		case tok.is(tokPunct, "|"):
+			if empty {
+				c.out.after("ε")
+			}
+			c.out.emit(c.next(), "|")
+			empty = true
+		case tok.kind == tokAction || tok.kind == tokBracket || tok.kind == tokTag:
+			c.next()
+		case tok.kind == tokDirective:
+			c.next()
+			switch tok.text {
+			case "empty":
+				c.out.emit(tok, "ε")
+				empty = false
+			case "prec":
+				c.next()
+			case "dprec", "merge":
+				return errorAt(tok, "GLR directive %%%s is not supported", tok.text)
+			default:
+				return errorAt(tok, "unsupported directive %%%s in rule", tok.text)
+			}
+		case tok.is(tokIdent, "error"):
+			return errorAt(tok, "the error token of yacc error recovery is not supported")
+		case tok.kind == tokIdent:
+			c.next()
+			if name := c.ident(tok.text); c.decls.seen[name] {
+				c.out.emit(tok, name)
+			} else {
+				c.out.emit(tok, "<"+name+">")
+			}
+			empty = false
+		case tok.kind == tokLiteral:
+			c.next()
+			if tok.text == "" {
+				return errorAt(tok, "empty literal")
+			}
+			if name, ok := c.aliases[tok.text]; ok {
+				c.out.emit(tok, name)
+				empty = false
+				continue
+			}
+			c.out.terminal(tok, tok.text)
+			empty = false
+		default:
+			return errorAt(tok, "unsupported %q in rule", tok.text)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ll1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictKind
+// LL(1)冲突的类型
+type ConflictKind int
+
+const (
+	// FirstFirst 两个备选项的first集相交
+	FirstFirst ConflictKind = iota
+	// FirstFollow 一个备选项可以推出空串，另一个备选项的first集与左部的follow集相交
+	FirstFollow
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case FirstFirst:
+		return "FIRST/FIRST"
+	case FirstFollow:
+		return "FIRST/FOLLOW"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", int(k))
+}
+
+// Conflict
+// 同一个非终结符的两个备选项的select集相交，Lookahead是相交的终结符。
+// Resolved为true时每个相交的终结符都由%prefer指定了备选项，冲突只作为警告
+type Conflict struct {
+	NonTerminal Symbol
+	First       Alternative
+	Second      Alternative
+	Lookahead   []Symbol
+	Kind        ConflictKind
+	Resolved    bool
+}
+
+func (c Conflict) String() string {
+	lookahead := make([]string, len(c.Lookahead))
+	for i, s := range c.Lookahead {
+		lookahead[i] = s.Value
+	}
+	s := fmt.Sprintf("%s conflict on %s: select(%s -> %s)∩select(%s -> %s) = {%s}",
+		c.Kind, c.NonTerminal.Value,
+		c.NonTerminal.Value, SymbolsToString(c.First.Symbols),
+		c.NonTerminal.Value, SymbolsToString(c.Second.Symbols),
+		strings.Join(lookahead, ", "))
+	if c.Resolved {
+		s += " (resolved by %prefer)"
+	}
+	return s
+}
+
+// Conflicts
+// 对每个非终结符A的每对备选项α、β，若select(A->α)∩select(A->β)不为空则记录一个冲突：
+// first(α)与first(β)（除ε外）相交时为FIRST/FIRST冲突，否则是由follow(A)引起的FIRST/FOLLOW冲突。
+// 需要先计算Nullable、FirstSet和FollowSet
+func (g Grammar) Conflicts() []Conflict {
+	var conflicts []Conflict
+	for _, prod := range g.Productions {
+		for i := 0; i < len(prod.Right); i++ {
+			for j := i + 1; j < len(prod.Right); j++ {
+				select1 := g.Select(prod.Left, prod.Right[i].Symbols)
+				select2 := g.Select(prod.Left, prod.Right[j].Symbols)
+				overlap := Intersection(select1, select2)
+				if len(overlap) == 0 {
+					continue
+				}
+				kind := FirstFollow
+				first1 := deleteEpsilon(g.GetFirst(prod.Right[i].Symbols))
+				first2 := deleteEpsilon(g.GetFirst(prod.Right[j].Symbols))
+				if HasIntersection(first1, first2) {
+					kind = FirstFirst
+				}
+				c := Conflict{
+					NonTerminal: prod.Left,
+					First:       prod.Right[i],
+					Second:      prod.Right[j],
+					Lookahead:   overlap,
+					Kind:        kind,
+				}
+				c.Resolved = g.resolves(c)
+				conflicts = append(conflicts, c)
+			}
+		}
+	}
+	return conflicts
+}
+
+// UnresolvedConflicts
+// 返回没有被%prefer解决的冲突
+func (g Grammar) UnresolvedConflicts() []Conflict {
+	var result []Conflict
+	for _, c := range g.Conflicts() {
+		if !c.Resolved {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Intersection
+// 返回两个符号集的交集，按slice1中的顺序排列
+func Intersection(slice1, slice2 []Symbol) []Symbol {
+	elementMap := make(map[Symbol]bool)
+	for _, elem := range slice2 {
+		elementMap[elem] = true
+	}
+	var result []Symbol
+	for _, elem := range slice1 {
+		if elementMap[elem] {
+			result = append(result, elem)
+			// 避免slice1中的重复元素
+			delete(elementMap, elem)
+		}
+	}
+	return result
+}
+
+func deleteEpsilon(symbols []Symbol) []Symbol {
+	result := make([]Symbol, 0, len(symbols))
+	for _, s := range symbols {
+		if !s.IsEpsilon() {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,199 @@
+package ll1
+
+import "fmt"
+
+// EBNF
+//
+//	expr   -> term { ('+' | '-') term }
+//	call   -> id '(' [ args ] ')'
+//	args   -> expr ( ',' expr )*
+//	digits -> digit+
+//
+// 产生式右部可以使用 ( ) 分组，[ ] 和后缀 ? 表示可选，{ } 和后缀 * 表示重复零次或多次，后缀 + 表示重复一次或多次。
+// 解析时展开为普通的产生式，每个运算符引入一个以所在产生式左部命名的辅助非终结符：
+//
+//	A_group1 -> α | β          ( α | β )，只有一个备选项的分组直接展开，不引入非终结符
+//	A_opt1   -> α | β | ε      [ α | β ] 或 ( α | β )?
+//	A_rep1   -> α A_rep1 | ε   { α } 或 α*，α+ 展开为 α A_rep1
+//
+// 重复展开为右递归，展开结果不需要消除左递归。辅助产生式排在所在产生式之后，位置是运算符的位置。
+// 括号中不能只有ε，可选和重复的内容本身不能推出ε，否则展开后会有重复的ε备选项或者环。
+
+// item
+// item := primary [ '*' | '+' | '?' ]
+// primary := symbol | '(' alternatives ')' | '[' alternatives ']' | '{' alternatives '}'
+// 返回展开后放在所在备选项中的符号
+func (p *notationParser) item() ([]Symbol, error) {
+	var alts []Alternative
+	tok := p.next()
+	if tok.kind == notationOpen {
+		if p.left.Value == "" {
+			return nil, p.errorf(tok, "EBNF operator %q is only allowed in productions", tok.text)
+		}
+		var err error
+		if alts, err = p.group(tok); err != nil {
+			return nil, err
+		}
+		if tok.text != "(" && p.derivesEpsilon(alts) {
+			return nil, p.errorf(tok, "operand of %q already derives ε", tok.text)
+		}
+		switch tok.text {
+		case "[":
+			alts = []Alternative{{Symbols: []Symbol{p.optional(tok, alts)}}}
+		case "{":
+			alts = []Alternative{{Symbols: []Symbol{p.repeat(tok, alts)}}}
+		}
+	} else {
+		sym, err := tokenSymbol(tok)
+		if err != nil {
+			return nil, err
+		}
+		alts = []Alternative{{Symbols: []Symbol{sym}}}
+	}
+
+	op := p.peek()
+	if op.kind != notationRepeat {
+		return p.inline(tok, alts), nil
+	}
+	p.next()
+	if p.left.Value == "" {
+		return nil, p.errorf(op, "EBNF operator %q is only allowed in productions", op.text)
+	}
+	// 可选的内容再可选会重复ε，重复可以为空的内容会形成环
+	if p.derivesEpsilon(alts) {
+		return nil, p.errorf(op, "operand of %q already derives ε", op.text)
+	}
+	switch op.text {
+	case "?":
+		return []Symbol{p.optional(op, alts)}, nil
+	case "*":
+		return []Symbol{p.repeat(op, alts)}, nil
+	}
+	// α+ = α α*
+	return append(p.inline(op, alts), p.repeat(op, alts)), nil
+}
+
+// group
+// 解析括号中用 | 分隔的备选项，直到匹配的右括号
+func (p *notationParser) group(open notationToken) ([]Alternative, error) {
+	closing := map[string]string{"(": ")", "[": "]", "{": "}"}[open.text]
+	var alts []Alternative
+	for {
+		alt, err := p.alternative()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, alt)
+		if p.peek().kind != notationBar {
+			break
+		}
+		p.next()
+	}
+	if tok := p.next(); tok.kind != notationClose || tok.text != closing {
+		return nil, p.errorf(tok, "expected %q to close %q at %d:%d, found %q", closing, open.text, open.line, open.column, tok.text)
+	}
+	empty := true
+	for _, alt := range alts {
+		for _, sym := range alt.Symbols {
+			if !sym.IsEpsilon() {
+				empty = false
+			}
+		}
+	}
+	if empty {
+		return nil, p.errorf(open, "empty group %s%s", open.text, closing)
+	}
+	return alts, nil
+}
+
+// derivesEpsilon
+// 备选项中是否有只由ε和可以为空的辅助非终结符组成的，用户定义的非终结符此时还不知道能否为空
+func (p *notationParser) derivesEpsilon(alts []Alternative) bool {
+	for _, alt := range alts {
+		nullable := true
+		for _, sym := range alt.Symbols {
+			if !sym.IsEpsilon() && !p.nullable[sym.Value] {
+				nullable = false
+				break
+			}
+		}
+		if nullable {
+			return true
+		}
+	}
+	return false
+}
+
+// inline
+// 只有一个备选项时直接返回它的符号，否则引入 A_groupN -> α | β
+func (p *notationParser) inline(at notationToken, alts []Alternative) []Symbol {
+	if len(alts) == 1 {
+		return alts[0].Symbols
+	}
+	return []Symbol{p.helper("group", at, alts)}
+}
+
+// optional
+// 引入 A_optN -> α | β | ε
+func (p *notationParser) optional(at notationToken, alts []Alternative) Symbol {
+	right := append(copyAlternatives(alts), Alternative{Symbols: []Symbol{Epsilon}})
+	return p.helper("opt", at, right)
+}
+
+// repeat
+// 引入 A_repN -> α A_repN | β A_repN | ε
+func (p *notationParser) repeat(at notationToken, alts []Alternative) Symbol {
+	name := p.freshName("rep")
+	right := make([]Alternative, 0, len(alts)+1)
+	for _, alt := range alts {
+		right = append(right, Alternative{Symbols: concatSymbols(alt.Symbols, []Symbol{name}), Pos: alt.Pos})
+	}
+	right = append(right, Alternative{Symbols: []Symbol{Epsilon}})
+	p.addHelper(name, at, right)
+	return name
+}
+
+// helper
+// 用新的名字引入一个辅助非终结符
+func (p *notationParser) helper(kind string, at notationToken, right []Alternative) Symbol {
+	name := p.freshName(kind)
+	p.addHelper(name, at, right)
+	return name
+}
+
+func (p *notationParser) addHelper(name Symbol, at notationToken, right []Alternative) {
+	pos := Position{Line: at.line, Column: at.column}
+	for i := range right {
+		if right[i].Pos.Column == 0 {
+			right[i].Pos = pos
+		}
+	}
+	p.helpers = append(p.helpers, Production{Left: name, Right: right, Pos: pos})
+	if p.derivesEpsilon(right) {
+		p.nullable[name.Value] = true
+	}
+}
+
+// freshName
+// 返回 左部_kindN 形式的名字，N从1开始，跳过记法中已经出现过的名字
+func (p *notationParser) freshName(kind string) Symbol {
+	key := p.left.Value + "_" + kind
+	for {
+		p.counts[key]++
+		name := fmt.Sprintf("%s%d", key, p.counts[key])
+		if !p.names[name] {
+			p.names[name] = true
+			return Symbol{Value: name, IsTerminal: false}
+		}
+	}
+}
+
+// copyAlternatives
+// 复制备选项，辅助产生式之间不共享符号切片
+func copyAlternatives(alts []Alternative) []Alternative {
+	result := make([]Alternative, len(alts))
+	for i, alt := range alts {
+		result[i] = Alternative{Symbols: append([]Symbol(nil), alt.Symbols...), Pos: alt.Pos}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package ll1
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEBNF(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			// 辅助产生式排在所在产生式之后，内层的先引入
+			name: "group in repetition",
+			src:  "expr -> term { ('+' | '-') term } ; term -> id ;",
+			want: []string{
+				"expr -> term expr_rep1 @1:1",
+				"expr_group1 -> +|- @1:16",
+				"expr_rep1 -> expr_group1 term expr_rep1|ε @1:14",
+				"term -> id @1:37",
+			},
+		},
+		{
+			name: "optional and star",
+			src:  "call -> id '(' [ args ] ')' ; args -> e (',' e)* ; e -> x ;",
+			want: []string{
+				"call -> id ( call_opt1 ) @1:1",
+				"call_opt1 -> args|ε @1:16",
+				"args -> e args_rep1 @1:31",
+				"args_rep1 -> , e args_rep1|ε @1:48",
+				"e -> x @1:52",
+			},
+		},
+		{
+			// α+ 展开为 α A_rep1，辅助产生式按运算符出现的顺序排列
+			name: "plus",
+			src:  "S -> a+ b? ;",
+			want: []string{
+				"S -> a S_rep1 S_opt1 @1:1",
+				"S_rep1 -> a S_rep1|ε @1:7",
+				"S_opt1 -> b|ε @1:10",
+			},
+		},
+		{
+			name: "nested",
+			src:  "S -> { a [ b | c ] } d ;",
+			want: []string{
+				"S -> S_rep1 d @1:1",
+				"S_opt1 -> b|c|ε @1:10",
+				"S_rep1 -> a S_opt1 S_rep1|ε @1:6",
+			},
+		},
+		{
+			name: "single alternative group",
+			src:  "S -> ( a b ) c | (d | e)? ;",
+			want: []string{
+				"S -> abc|S_opt1 @1:1",
+				"S_opt1 -> d|e|ε @1:25",
+			},
+		},
+		{
+			// 跳过用户定义的 S_rep1 和 S_opt1
+			name: "name taken",
+			src:  "S -> { a } [ b ] S_rep1 ; S_rep1 -> c ; S_opt1 -> d ;",
+			want: []string{
+				"S -> S_rep2 S_opt2 S_rep1 @1:1",
+				"S_rep2 -> a S_rep2|ε @1:6",
+				"S_opt2 -> b|ε @1:12",
+				"S_rep1 -> c @1:27",
+				"S_opt1 -> d @1:41",
+			},
+		},
+		{
+			name: "helper of helper name",
+			src:  "S -> S_rep1 ; S_rep1 -> { b } ;",
+			want: []string{
+				"S -> S_rep1 @1:1",
+				"S_rep1 -> S_rep1_rep1 @1:15",
+				"S_rep1_rep1 -> b S_rep1_rep1|ε @1:25",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range g.Productions {
+				got = append(got, p.String()+" @"+p.Pos.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestEBNFErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		pos  string
+		want string
+	}{
+		{"S -> [] ;", "1:6", "empty group []"},
+		{"S -> {}* ;", "1:6", "empty group {}"},
+		{"S -> ( ) a ;", "1:6", "empty group ()"},
+		{"S -> a [ ε | ] ;", "1:8", "empty group []"},
+		{"S -> [ a | ε ] ;", "1:6", `operand of "[" already derives ε`},
+		{"S -> { a* } ;", "1:6", `operand of "{" already derives ε`},
+		{"S -> (a?)* ;", "1:10", `operand of "*" already derives ε`},
+		{"S -> [a]+ ;", "1:9", `operand of "+" already derives ε`},
+		{"S -> { a }+ ;", "1:11", `operand of "+" already derives ε`},
+		{"S -> (a | b*)? ;", "1:14", `operand of "?" already derives ε`},
+		{"S -> a ( b c ;", "1:14", `expected ")" to close "(" at 1:8, found ";"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := ParseGrammar(tt.src)
+			var e *SyntaxError
+			if !errors.As(err, &e) {
+				t.Fatalf("ParseGrammar() = %v, want SyntaxError", err)
+			}
+			if pos := (Position{Line: e.Line, Column: e.Column}).String(); pos != tt.pos || e.Msg != tt.want {
+				t.Errorf("got %s: %s, want %s: %s", pos, e.Msg, tt.pos, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package ll1 实现了LL(1)文法的分析：提取终结符与非终结符，
+// 提取左公因子、消除左递归，计算nullable、first、follow、select集，
+// 判断文法是否为LL(1)文法，构造预测分析表，并用表驱动的方式分析输入串。
+//
+// 包内不做任何输出，所有结果都以值的形式返回，由调用方决定如何展示。
+package ll1
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SymbolKind
+// 符号的种类，文法中的终结符和非终结符是GrammarSymbol，由IsTerminal区分
+type SymbolKind int
+
+const (
+	// GrammarSymbol 终结符或非终结符
+	GrammarSymbol SymbolKind = iota
+	// EpsilonMarker 空串ε，它既不是终结符也不是非终结符，只能单独作为一个备选项
+	EpsilonMarker
+	// EndMarker 输入结束符，出现在follow集、select集和预测分析表的列中，不能在产生式中使用
+	EndMarker
+)
+
+// DefaultEndMarker 文法没有指定结束符时使用的结束符
+const DefaultEndMarker = "#"
+
+type Symbol struct {
+	Value      string
+	IsTerminal bool
+	// Kind 符号的种类，终结符和非终结符为零值
+	Kind SymbolKind
+}
+
+// Epsilon 空串ε，输入中写作ε、eps或空的备选项
+var Epsilon = Symbol{Value: "ε", Kind: EpsilonMarker}
+
+// IsEpsilon
+// 符号是否为空串ε
+func (s Symbol) IsEpsilon() bool {
+	return s.Kind == EpsilonMarker
+}
+
+// IsEnd
+// 符号是否为输入结束符
+func (s Symbol) IsEnd() bool {
+	return s.Kind == EndMarker
+}
+
+// IsNonTerminal
+// 符号是否为非终结符，ε不是非终结符
+func (s Symbol) IsNonTerminal() bool {
+	return s.Kind == GrammarSymbol && !s.IsTerminal
+}
+
+type Alternative struct {
+	Symbols []Symbol
+	// Pos 备选项在输入中的位置，Column为0表示位置未知（例如改写时新产生的备选项）
+	Pos Position
+}
+type Production struct {
+	Left  Symbol
+	Right []Alternative
+	// Pos 产生式左部在输入中的位置
+	Pos Position
+}
+type Grammar struct {
+	Start       Symbol
+	Productions []Production
+	Nullable    map[string]bool
+	FirstSet    map[Symbol]*SymbolSet
+	FollowSet   map[Symbol]*SymbolSet
+	Predict     map[Symbol]map[Symbol]Production
+	// Lexer 输入串的词法分析器，为nil时每个终结符按自身的名字匹配，并跳过空白
+	Lexer *Lexer
+	// Rewrites GInit中依次对文法做的改写
+	Rewrites []Rewrite
+	// Preferences 解决冲突的%prefer指令
+	Preferences []Preference
+	// Declared 文法文件中用%token声明的终结符（true）和用<>写出的非终结符（false）。
+	// 没有产生式的<x>是未定义的符号；有%token声明时，没有声明也没有产生式的标识符也是未定义的符号
+	Declared map[string]bool
+	// RemoveUseless 为true时GInit先删除无用的符号再计算各个集合
+	RemoveUseless bool
+	// End 输入结束符，为空时使用DefaultEndMarker；它不能是文法中的终结符，常用的还有$
+	End string
+}
+
+// NewGrammar
+// 用开始符和产生式集创建文法，集合与分析表由GInit计算
+func NewGrammar(start Symbol, prods []Production) *Grammar {
+	return &Grammar{
+		Start:       start,
+		Productions: prods,
+	}
+}
+
+// EndSymbol
+// 文法的输入结束符
+func (g *Grammar) EndSymbol() Symbol {
+	end := g.End
+	if end == "" {
+		end = DefaultEndMarker
+	}
+	return Symbol{Value: end, Kind: EndMarker}
+}
+
+// lookahead
+// 输入中单词的种类对应的向前看符号：结束符的单词对应EndSymbol，其余是终结符
+func (g *Grammar) lookahead(kind string) Symbol {
+	if end := g.EndSymbol(); kind == end.Value {
+		return end
+	}
+	return Symbol{Value: kind, IsTerminal: true}
+}
+
+// GetNonTerminals
+// 获取输出的文法所有产生式的非终结符，按产生式左部第一次出现的顺序排列
+func (g *Grammar) GetNonTerminals() []Symbol {
+	nonTerminals := make(map[string]bool)
+	result := make([]Symbol, 0)
+
+	// 遍历所有产生式规则，将左部符号加入集合中
+	for _, production := range g.Productions {
+		if !nonTerminals[production.Left.Value] {
+			nonTerminals[production.Left.Value] = true
+			result = append(result, Symbol{Value: production.Left.Value, IsTerminal: false})
+		}
+	}
+	return result
+}
+
+// GetTerminals
+// 获取输出的文法所有产生式的终结符，按在产生式右部第一次出现的顺序排列
+func (g *Grammar) GetTerminals() []Symbol {
+	// 从终结符中排除非终结符
+	nonTerminals := make(map[string]bool)
+	for _, nt := range g.GetNonTerminals() {
+		nonTerminals[nt.Value] = true
+	}
+	terminals := make(map[string]bool)
+	result := make([]Symbol, 0)
+	// 遍历产生式右部得到所有符号
+	for _, production := range g.Productions {
+		for _, alternative := range production.Right {
+			for _, sym := range alternative.Symbols {
+				// 如果符号不是非终结符也不是ε，则将其加入终结符集合
+				if !sym.IsEpsilon() && !nonTerminals[sym.Value] && !terminals[sym.Value] {
+					terminals[sym.Value] = true
+					result = append(result, Symbol{Value: sym.Value, IsTerminal: true})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// MarkTerminals
+// 输入时所有符号都被视为非终结符，这里根据GetTerminals的结果更新符号的 IsTerminal 字段。
+// 空的备选项和值为ε的符号统一为Epsilon
+func (g *Grammar) MarkTerminals() {
+	g.normalizeEpsilon()
+	terminals := g.GetTerminals()
+	terminalSet := make(map[string]bool)
+	for _, t := range terminals {
+		terminalSet[t.Value] = true
+	}
+	for i, production := range g.Productions {
+		for j, alternative := range production.Right {
+			for k, sym := range alternative.Symbols {
+				if terminalSet[sym.Value] {
+					g.Productions[i].Right[j].Symbols[k].IsTerminal = true
+				}
+			}
+		}
+	}
+}
+
+// normalizeEpsilon
+// 把空的备选项和直接构造的值为ε的符号（如 Epsilon）换成Epsilon
+func (g *Grammar) normalizeEpsilon() {
+	for i, production := range g.Productions {
+		for j, alternative := range production.Right {
+			if len(alternative.Symbols) == 0 {
+				g.Productions[i].Right[j].Symbols = []Symbol{Epsilon}
+				continue
+			}
+			for k, sym := range alternative.Symbols {
+				if sym.Value == Epsilon.Value {
+					g.Productions[i].Right[j].Symbols[k] = Epsilon
+				}
+			}
+		}
+	}
+}
+
+// String
+// 以 A -> α|β 的形式按行返回文法的所有产生式
+func (g *Grammar) String() string {
+	var sb strings.Builder
+	for _, prod := range g.Productions {
+		sb.WriteString(prod.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// String
+// 以 A -> α|β 的形式返回产生式
+func (p Production) String() string {
+	rightParts := make([]string, len(p.Right))
+	for i, alt := range p.Right {
+		rightParts[i] = SymbolsToString(alt.Symbols)
+	}
+	return p.Left.Value + " -> " + strings.Join(rightParts, "|")
+}
+
+// SymbolsToString
+// 将符号串拼接为字符串，全是单字符符号时直接相连，否则用空格分隔
+func SymbolsToString(symbols []Symbol) string {
+	values := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		values[i] = symbol.Value
+	}
+	return JoinSymbols(values)
+}
+
+// JoinSymbols
+// 将符号值拼接为字符串，全是单字符符号时直接相连，否则用空格分隔
+func JoinSymbols(values []string) string {
+	for _, v := range values {
+		if utf8.RuneCountInString(v) > 1 {
+			return strings.Join(values, " ")
+		}
+	}
+	return strings.Join(values, "")
+}
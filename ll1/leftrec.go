@@ -0,0 +1,259 @@
+package ll1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CycleError
+// 文法中存在 A=>+A 的推导，消除左递归的算法要求文法没有环
+type CycleError struct {
+	Path []Symbol
+}
+
+func (e *CycleError) Error() string {
+	return "grammar has a cycle " + pathString(e.Path) + ", left recursion cannot be eliminated"
+}
+
+// HiddenLeftRecursionError
+// 左递归藏在可空的前缀后面，例如 A->BAa、B->ε，代入法无法消除这种左递归
+type HiddenLeftRecursionError struct {
+	NonTerminal Symbol
+	Path        []Symbol
+}
+
+func (e *HiddenLeftRecursionError) Error() string {
+	return fmt.Sprintf("%s is left recursive behind a nullable prefix (%s), remove the ε-productions first",
+		e.NonTerminal.Value, pathString(e.Path))
+}
+
+func pathString(path []Symbol) string {
+	values := make([]string, len(path))
+	for i, s := range path {
+		values[i] = s.Value
+	}
+	return strings.Join(values, " => ")
+}
+
+// leftCornerEdge
+// A->X1…Xk-1 B…，X1…Xk-1都可空时，A的最左推导可以得到B；hidden表示前缀不为空
+type leftCornerEdge struct {
+	to     Symbol
+	hidden bool
+}
+
+// EliminateLeftRecursion
+// 消除直接和间接左递归。将非终结符按声明顺序排为A1…An：
+//
+//	for i := 1..n
+//	    for j := 1..i-1
+//	        把形如 Ai->Ajγ 的产生式替换为 Ai->δ1γ|δ2γ|…|δkγ，其中 Aj->δ1|δ2|…|δk
+//	    消除Ai的直接左递归
+//
+// 只替换能最左推导出Ai的Aj，没有左递归的非终结符保持不变。
+// 算法要求文法没有环，也没有藏在可空前缀后面的左递归，否则分别返回CycleError和HiddenLeftRecursionError；
+// 某个非终结符的备选项都是左递归时返回EmptyProductionError。出错时文法保持不变
+func (g *Grammar) EliminateLeftRecursion() (Rewrite, error) {
+	rewrite := Rewrite{Name: "eliminateLeftRecursion"}
+	g.InitializeNullable()
+	order := g.nonTerminalOrder()
+	alts := g.alternativesByLeft()
+	if err := g.checkCycles(order, alts); err != nil {
+		return rewrite, err
+	}
+	edges := g.leftCorners(alts)
+	if err := checkLeftCorners(order, edges); err != nil {
+		return rewrite, err
+	}
+
+	var added []Production
+	for i, ai := range order {
+		if !reaches(edges, ai, ai) {
+			continue
+		}
+		for _, aj := range order[:i] {
+			if !reaches(edges, aj, ai) {
+				continue
+			}
+			var substituted []Alternative
+			for _, alt := range alts[ai] {
+				if len(alt.Symbols) == 0 || alt.Symbols[0] != aj {
+					substituted = append(substituted, alt)
+					continue
+				}
+				gamma := alt.Symbols[1:]
+				for _, delta := range alts[aj] {
+					substituted = append(substituted, Alternative{Symbols: concatSymbols(delta.Symbols, gamma)})
+				}
+			}
+			alts[ai] = substituted
+		}
+		prime := g.freshNonTerminal(ai.Value+"'", "'", rewrite.Added...)
+		right, primeRight, ok := eliminateDirect(ai, alts[ai], prime)
+		if !ok {
+			continue
+		}
+		if len(right) == 0 {
+			return rewrite, &EmptyProductionError{NonTerminal: ai, Pos: g.productionPos(ai), LeftRecursive: true}
+		}
+		alts[ai] = right
+		added = append(added, Production{Left: prime, Right: primeRight})
+		rewrite.Added = append(rewrite.Added, prime)
+	}
+	if len(added) == 0 {
+		rewrite.Productions = copyProductions(g.Productions)
+		return rewrite, nil
+	}
+
+	prods := make([]Production, 0, len(order)+len(added))
+	for _, nt := range order {
+		prods = append(prods, Production{Left: nt, Right: alts[nt]})
+	}
+	g.Productions = append(prods, added...)
+	rewrite.Changed = true
+	rewrite.Productions = copyProductions(g.Productions)
+	return rewrite, nil
+}
+
+// productionPos
+// 非终结符的产生式在文法文件中的位置
+func (g *Grammar) productionPos(nt Symbol) Position {
+	for _, prod := range g.Productions {
+		if prod.Left.Value == nt.Value {
+			return prod.Pos
+		}
+	}
+	return Position{}
+}
+
+// eliminateDirect
+// 消除nt的直接左递归，返回nt新的备选项和prime的备选项，没有直接左递归时ok为false
+// A→Aα1|Aα2|…|Aαm|β1|β2|…|βn
+// 消除后为
+// A→(β1|β2|…|βn)A’
+// A’→(α1|α2|…|αm)A’|ε
+func eliminateDirect(nt Symbol, alts []Alternative, prime Symbol) (right, primeRight []Alternative, ok bool) {
+	var alpha, beta []Alternative
+	for _, alt := range alts {
+		if len(alt.Symbols) > 0 && alt.Symbols[0].Value == nt.Value {
+			alpha = append(alpha, Alternative{Symbols: alt.Symbols[1:]})
+		} else {
+			beta = append(beta, alt)
+		}
+	}
+	if len(alpha) == 0 {
+		return alts, nil, false
+	}
+	for _, b := range beta {
+		right = append(right, Alternative{Symbols: concatSymbols(b.Symbols, []Symbol{prime})})
+	}
+	for _, a := range alpha {
+		primeRight = append(primeRight, Alternative{Symbols: concatSymbols(a.Symbols, []Symbol{prime})})
+	}
+	primeRight = append(primeRight, Alternative{Symbols: []Symbol{Epsilon}})
+	return right, primeRight, true
+}
+
+// concatSymbols
+// 拼接两个符号串，去掉其中的ε，结果为空时返回ε
+func concatSymbols(a, b []Symbol) []Symbol {
+	result := make([]Symbol, 0, len(a)+len(b))
+	for _, s := range append(append([]Symbol(nil), a...), b...) {
+		if !s.IsEpsilon() {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, Epsilon)
+	}
+	return result
+}
+
+// leftCorners
+// 计算每个非终结符在最左推导中可以直接得到的非终结符，需要先计算Nullable
+func (g *Grammar) leftCorners(alts map[Symbol][]Alternative) map[Symbol][]leftCornerEdge {
+	edges := make(map[Symbol][]leftCornerEdge)
+	for nt, right := range alts {
+		for _, alt := range right {
+			for k, sym := range alt.Symbols {
+				if !sym.IsNonTerminal() {
+					break
+				}
+				edges[nt] = append(edges[nt], leftCornerEdge{to: sym, hidden: k > 0})
+				if !g.Nullable[sym.Value] {
+					break
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// checkCycles
+// 检查文法中是否有 A=>+A 的环，需要先计算Nullable
+func (g *Grammar) checkCycles(order []Symbol, alts map[Symbol][]Alternative) error {
+	// A->αBβ 且 α、β都可空时 A=>B，这样的边构成的环就是 A=>+A
+	unit := make(map[Symbol][]leftCornerEdge)
+	for nt, right := range alts {
+		for _, alt := range right {
+			for k, sym := range alt.Symbols {
+				if !sym.IsNonTerminal() || !g.AllNullable(alt.Symbols[:k]) || !g.AllNullable(alt.Symbols[k+1:]) {
+					continue
+				}
+				unit[nt] = append(unit[nt], leftCornerEdge{to: sym})
+			}
+		}
+	}
+	for _, nt := range order {
+		if path := findPath(unit, nt, nt, nil); path != nil {
+			return &CycleError{Path: path}
+		}
+	}
+	return nil
+}
+
+// checkLeftCorners
+// 检查藏在可空前缀后面的左递归：A->αBβ中α可空但不为空，而B又能最左推导出A
+func checkLeftCorners(order []Symbol, edges map[Symbol][]leftCornerEdge) error {
+	for _, nt := range order {
+		for _, e := range edges[nt] {
+			if !e.hidden {
+				continue
+			}
+			if e.to == nt {
+				return &HiddenLeftRecursionError{NonTerminal: nt, Path: []Symbol{nt, nt}}
+			}
+			if path := findPath(edges, e.to, nt, nil); path != nil {
+				return &HiddenLeftRecursionError{NonTerminal: nt, Path: append([]Symbol{nt}, path...)}
+			}
+		}
+	}
+	return nil
+}
+
+// reaches
+// from能否通过一条或多条边到达to
+func reaches(edges map[Symbol][]leftCornerEdge, from, to Symbol) bool {
+	return findPath(edges, from, to, nil) != nil
+}
+
+// findPath
+// 深度优先搜索从from出发经过至少一条边到达to的路径，返回路径上的符号（包括from和to），找不到时返回nil
+func findPath(edges map[Symbol][]leftCornerEdge, from, to Symbol, visited map[Symbol]bool) []Symbol {
+	if visited == nil {
+		visited = make(map[Symbol]bool)
+	}
+	visited[from] = true
+	for _, e := range edges[from] {
+		if e.to == to {
+			return []Symbol{from, to}
+		}
+		if visited[e.to] {
+			continue
+		}
+		if path := findPath(edges, e.to, to, visited); path != nil {
+			return append([]Symbol{from}, path...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package ll1
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// leftRecGrammar
+// 读入文法并标记终结符，得到EliminateLeftRecursion的输入
+func leftRecGrammar(t *testing.T, src string) *Grammar {
+	t.Helper()
+	g, err := ParseGrammar(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.normalizeEpsilon()
+	g.MarkTerminals()
+	return g
+}
+
+func TestEliminateLeftRecursion(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		added   string
+		changed bool
+	}{
+		{
+			name:    "direct",
+			src:     "E -> E '+' T | T ; T -> id ;",
+			want:    []string{"E -> T E'", "T -> id", "E' -> + T E'|ε"},
+			added:   "E'",
+			changed: true,
+		},
+		{
+			name:    "indirect",
+			src:     "S -> A a | b ; A -> S c | d ;",
+			want:    []string{"S -> Aa|b", "A -> b c A'|d A'", "A' -> a c A'|ε"},
+			added:   "A'",
+			changed: true,
+		},
+		{
+			name:    "three nonterminal cycle",
+			src:     "S -> A a | b ; A -> B c | d ; B -> S e | f ;",
+			want:    []string{"S -> Aa|b", "A -> Bc|d", "B -> d a e B'|b e B'|f B'", "B' -> c a e B'|ε"},
+			added:   "B'",
+			changed: true,
+		},
+		{
+			name:  "prime name taken",
+			src:   "S -> S a | S' ; S' -> b ;",
+			want:  []string{"S -> S' S''", "S' -> b", "S'' -> a S''|ε"},
+			added: "S''", changed: true,
+		},
+		{
+			name: "no left recursion",
+			src:  "S -> a S | b ;",
+			want: []string{"S -> aS|b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := leftRecGrammar(t, tt.src)
+			rewrite, err := g.EliminateLeftRecursion()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Split(strings.TrimSuffix(g.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if got := SymbolsToString(rewrite.Added); got != tt.added {
+				t.Errorf("Added = %q, want %q", got, tt.added)
+			}
+			if rewrite.Changed != tt.changed {
+				t.Errorf("Changed = %v, want %v", rewrite.Changed, tt.changed)
+			}
+			for _, s := range rewrite.Added {
+				if !s.IsNonTerminal() {
+					t.Errorf("added symbol %s is not a nonterminal", s.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestEliminateLeftRecursionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want func(error) bool
+	}{
+		{"cycle", "S -> A | a ; A -> S ;", func(err error) bool {
+			var e *CycleError
+			return errors.As(err, &e) && SymbolsToString(e.Path) == "SAS"
+		}},
+		{"hidden left recursion", "S -> B S a | b ; B -> c | ε ;", func(err error) bool {
+			var e *HiddenLeftRecursionError
+			return errors.As(err, &e) && e.NonTerminal.Value == "S"
+		}},
+		{"only left recursive alternatives", "S -> a | b A ; A -> A c ;", func(err error) bool {
+			var e *EmptyProductionError
+			return errors.As(err, &e) && e.NonTerminal.Value == "A" && e.LeftRecursive
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := leftRecGrammar(t, tt.src)
+			before := g.String()
+			rewrite, err := g.EliminateLeftRecursion()
+			if !tt.want(err) {
+				t.Fatalf("EliminateLeftRecursion() = %v", err)
+			}
+			if got := g.String(); got != before {
+				t.Errorf("grammar changed on error:\n%s\nwant\n%s", got, before)
+			}
+			if rewrite.Changed || len(rewrite.Added) > 0 {
+				t.Errorf("rewrite = %+v, want no change", rewrite)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package ll1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position
+// 输入中的位置，Offset为字节偏移，Line和Column从1开始计数，Column按字符计算
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token
+// 词法分析得到的单词，Kind是对应的终结符，Text是匹配到的原文
+type Token struct {
+	Kind string
+	Text string
+	Pos  Position
+}
+
+// LexError
+// 输入中出现了不能匹配任何终结符的字符
+type LexError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *LexError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// lexRule
+// 一条词法规则，literal不为空时按原文匹配，否则按正则表达式匹配
+type lexRule struct {
+	terminal string
+	literal  string
+	re       *regexp.Regexp
+	// pattern re的原始写法
+	pattern string
+}
+
+// Lexer
+// 由终结符绑定的原文或正则表达式构成的词法分析器。
+// 每次取所有规则中最长的匹配，长度相同时原文优先于正则表达式（关键字优先于标识符），再按添加的顺序；
+// 跳过规则匹配的内容（空白、注释）不产生单词
+type Lexer struct {
+	rules []lexRule
+	skips []*regexp.Regexp
+}
+
+// NewLexer
+// 创建一个没有任何规则的词法分析器
+func NewLexer() *Lexer {
+	return &Lexer{}
+}
+
+// DefaultLexer
+// 为文法的每个终结符添加同名的原文规则，并跳过空白
+func DefaultLexer(g *Grammar) *Lexer {
+	l := NewLexer()
+	l.addTerminalLiterals(g)
+	l.skips = append(l.skips, regexp.MustCompile(`^\s+`))
+	return l
+}
+
+// Literal
+// 将终结符绑定到原文text，例如 while 绑定到 "while"
+func (l *Lexer) Literal(terminal, text string) {
+	l.rules = append(l.rules, lexRule{terminal: terminal, literal: text})
+}
+
+// Pattern
+// 将终结符绑定到正则表达式，例如 num 绑定到 [0-9]+
+func (l *Lexer) Pattern(terminal, expr string) error {
+	re, err := compileAnchored(expr)
+	if err != nil {
+		return err
+	}
+	l.rules = append(l.rules, lexRule{terminal: terminal, re: re, pattern: expr})
+	return nil
+}
+
+// Binding
+// 返回终结符绑定的原文或正则表达式，两者只有一个不为空；没有绑定时ok为false
+func (l *Lexer) Binding(terminal string) (literal, pattern string, ok bool) {
+	for _, r := range l.rules {
+		if r.terminal == terminal {
+			return r.literal, r.pattern, true
+		}
+	}
+	return "", "", false
+}
+
+// Skip
+// 添加一条跳过规则，匹配的内容（如空白、注释）不产生单词
+func (l *Lexer) Skip(expr string) error {
+	re, err := compileAnchored(expr)
+	if err != nil {
+		return err
+	}
+	l.skips = append(l.skips, re)
+	return nil
+}
+
+func compileAnchored(expr string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(`^(?:` + expr + `)`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern /%s/: %w", expr, err)
+	}
+	return re, nil
+}
+
+// addTerminalLiterals
+// 没有绑定任何规则的终结符按自身的名字匹配
+func (l *Lexer) addTerminalLiterals(g *Grammar) {
+	bound := make(map[string]bool)
+	for _, r := range l.rules {
+		bound[r.terminal] = true
+	}
+	for _, t := range g.GetTerminals() {
+		if !bound[t.Value] {
+			l.Literal(t.Value, t.Value)
+		}
+	}
+}
+
+// Tokenize
+// 将输入切分为单词序列，遇到不能匹配的字符时返回带位置的LexError
+func (l *Lexer) Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	pos := Position{Offset: 0, Line: 1, Column: 1}
+	for pos.Offset < len(input) {
+		rest := input[pos.Offset:]
+		if n := l.skipLength(rest); n > 0 {
+			pos = advance(pos, rest[:n])
+			continue
+		}
+		terminal, n := l.match(rest)
+		if n == 0 {
+			r, _ := utf8.DecodeRuneInString(rest)
+			return tokens, &LexError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+		tokens = append(tokens, Token{Kind: terminal, Text: rest[:n], Pos: pos})
+		pos = advance(pos, rest[:n])
+	}
+	return tokens, nil
+}
+
+func (l *Lexer) skipLength(rest string) int {
+	longest := 0
+	for _, re := range l.skips {
+		if loc := re.FindStringIndex(rest); loc != nil && loc[1] > longest {
+			longest = loc[1]
+		}
+	}
+	return longest
+}
+
+// match
+// 返回最长匹配的终结符和匹配的字节数
+func (l *Lexer) match(rest string) (string, int) {
+	terminal, longest, literal := "", 0, false
+	for _, r := range l.rules {
+		n, isLiteral := 0, r.literal != ""
+		if isLiteral {
+			if strings.HasPrefix(rest, r.literal) {
+				n = len(r.literal)
+			}
+		} else if loc := r.re.FindStringIndex(rest); loc != nil {
+			n = loc[1]
+		}
+		if n > longest || n == longest && n > 0 && isLiteral && !literal {
+			terminal, longest, literal = r.terminal, n, isLiteral
+		}
+	}
+	return terminal, longest
+}
+
+func advance(pos Position, text string) Position {
+	for _, r := range text {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	pos.Offset += len(text)
+	return pos
+}
+
+// Tokenize
+// 用文法的词法分析器切分输入：文法没有设置Lexer时使用DefaultLexer，
+// 设置了Lexer时，没有绑定规则的终结符仍按自身的名字匹配
+func (g Grammar) Tokenize(input string) ([]Token, error) {
+	if g.Lexer == nil {
+		return DefaultLexer(&g).Tokenize(input)
+	}
+	l := &Lexer{
+		rules: append([]lexRule(nil), g.Lexer.rules...),
+		skips: g.Lexer.skips,
+	}
+	l.addTerminalLiterals(&g)
+	return l.Tokenize(input)
+}
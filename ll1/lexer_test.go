@@ -0,0 +1,131 @@
+package ll1
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func tokenStrings(tokens []Token) string {
+	result := make([]string, len(tokens))
+	for i, tok := range tokens {
+		result[i] = tok.Kind + ":" + tok.Text + "@" + tok.Pos.String()
+	}
+	return strings.Join(result, " ")
+}
+
+func testLexer(t *testing.T) *Lexer {
+	t.Helper()
+	l := NewLexer()
+	l.Literal("=", "=")
+	l.Literal("==", "==")
+	// 关键字写在标识符之后，同样长时原文仍然优先
+	for _, p := range [][2]string{{"id", `[a-zé]+`}, {"num", `[0-9]+`}} {
+		if err := l.Pattern(p[0], p[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Literal("if", "if")
+	for _, skip := range []string{`\s+`, `//[^\n]*`} {
+		if err := l.Skip(skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"longest match", "a==b=c", "id:a@1:1 ==:==@1:2 id:b@1:4 =:=@1:5 id:c@1:6"},
+		{"literal over pattern", "if iffy", "if:if@1:1 id:iffy@1:4"},
+		{"skip rules", "x = 1 // one\n  // two\ny", "id:x@1:1 =:=@1:3 num:1@1:5 id:y@3:1"},
+		{"columns count characters", "éé = 2", "id:éé@1:1 =:=@1:4 num:2@1:6"},
+		{"empty", " \n ", ""},
+	}
+	l := testLexer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := l.Tokenize(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tokenStrings(tokens); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeError(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		want   string
+		before string
+	}{
+		{"a\n\tb ?", 5, `2:4: unexpected character '?'`, "id:a@1:1 id:b@2:2"},
+		{"x // c\n\n  é£", 12, `3:4: unexpected character '£'`, "id:x@1:1 id:é@3:3"},
+		{"A", 0, `1:1: unexpected character 'A'`, ""},
+	}
+	l := testLexer(t)
+	for _, tt := range tests {
+		tokens, err := l.Tokenize(tt.input)
+		var e *LexError
+		if !errors.As(err, &e) {
+			t.Fatalf("Tokenize(%q) = %v, want LexError", tt.input, err)
+		}
+		if e.Error() != tt.want || e.Pos.Offset != tt.offset {
+			t.Errorf("Tokenize(%q) = %v at offset %d, want %s at offset %d", tt.input, e, e.Pos.Offset, tt.want, tt.offset)
+		}
+		// 出错之前的单词也会返回
+		if got := tokenStrings(tokens); got != tt.before {
+			t.Errorf("Tokenize(%q) tokens = %s, want %s", tt.input, got, tt.before)
+		}
+	}
+}
+
+func TestLexerInvalidPattern(t *testing.T) {
+	l := NewLexer()
+	if err := l.Pattern("id", "[a-"); err == nil || !strings.HasPrefix(err.Error(), "invalid pattern /[a-/: ") {
+		t.Errorf("Pattern() = %v", err)
+	}
+	if err := l.Skip("("); err == nil {
+		t.Errorf("Skip() accepted an invalid pattern")
+	}
+}
+
+// TestGrammarTokenize
+// 没有设置Lexer时终结符按名字匹配并跳过空白，设置了Lexer时没有绑定的终结符仍按名字匹配
+func TestGrammarTokenize(t *testing.T) {
+	g, err := ParseGrammar("S -> id '+' S | id ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.MarkTerminals()
+	tokens, err := g.Tokenize("id+ id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenStrings(tokens); got != "id:id@1:1 +:+@1:3 id:id@1:5" {
+		t.Errorf("default lexer: %s", got)
+	}
+
+	g.Lexer = NewLexer()
+	if err := g.Lexer.Pattern("id", `[a-z]+`); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err = g.Tokenize("ab+c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenStrings(tokens); got != "id:ab@1:1 +:+@1:3 id:c@1:4" {
+		t.Errorf("custom lexer: %s", got)
+	}
+	if _, err := g.Tokenize("a + b"); err == nil {
+		t.Errorf("custom lexer without skip rules accepted spaces")
+	}
+}
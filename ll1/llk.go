@@ -0,0 +1,394 @@
+package ll1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LL(k) 分析
+//
+// 向前看串是k个终结符组成的序列，输入的末尾视为有k个结束符，所以每个向前看串的长度都正好是k。
+// 预测分析表按强LL(k)的方式构造：对每个产生式 A->α，
+// 把 A->α 加入 M[A,w]，w∈FIRST_k(α FOLLOW_k(A))。文法没有冲突时，k=1的表与InitializePredict的结果相同；
+// 有冲突时BuildLLk在冲突的表项中保留先出现的备选项，而InitializePredict保留后出现的备选项并应用%prefer。
+
+// seqSet
+// 保持顺序的终结符序列集合
+type seqSet struct {
+	seqs  [][]string
+	index map[string]bool
+}
+
+func newSeqSet() *seqSet {
+	return &seqSet{index: make(map[string]bool)}
+}
+
+func (s *seqSet) add(seq []string) bool {
+	key := seqKey(seq)
+	if s.index[key] {
+		return false
+	}
+	s.index[key] = true
+	s.seqs = append(s.seqs, seq)
+	return true
+}
+
+func (s *seqSet) addAll(other *seqSet) bool {
+	changed := false
+	for _, seq := range other.seqs {
+		if s.add(seq) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// seqKey
+// 序列在map中的键，终结符中不会出现\x1f
+func seqKey(seq []string) string {
+	return strings.Join(seq, "\x1f")
+}
+
+// concatK
+// {x·y 的前k个符号 | x∈a, y∈b}
+func concatK(a, b *seqSet, k int) *seqSet {
+	result := newSeqSet()
+	for _, x := range a.seqs {
+		if len(x) >= k {
+			result.add(x)
+			continue
+		}
+		for _, y := range b.seqs {
+			seq := append(append([]string(nil), x...), y...)
+			if len(seq) > k {
+				seq = seq[:k]
+			}
+			result.add(seq)
+		}
+	}
+	return result
+}
+
+// llkSets
+// FIRST_k和FOLLOW_k集
+type llkSets struct {
+	k      int
+	first  map[Symbol]*seqSet
+	follow map[Symbol]*seqSet
+}
+
+// firstK
+// 符号串的FIRST_k集，需要先计算出所有非终结符的FIRST_k集
+func (s *llkSets) firstK(symbols []Symbol) *seqSet {
+	result := newSeqSet()
+	result.add([]string{})
+	for _, sym := range symbols {
+		switch {
+		case sym.IsEpsilon():
+			continue
+		case sym.IsTerminal:
+			single := newSeqSet()
+			single.add([]string{sym.Value})
+			result = concatK(result, single, s.k)
+		default:
+			first, ok := s.first[sym]
+			if !ok {
+				first = newSeqSet()
+			}
+			result = concatK(result, first, s.k)
+		}
+	}
+	return result
+}
+
+// computeLLkSets
+// 反复遍历产生式计算FIRST_k和FOLLOW_k，直到集合不再变化
+func (g *Grammar) computeLLkSets(k int) *llkSets {
+	s := &llkSets{k: k, first: make(map[Symbol]*seqSet), follow: make(map[Symbol]*seqSet)}
+	for _, nt := range g.GetNonTerminals() {
+		s.first[nt] = newSeqSet()
+		s.follow[nt] = newSeqSet()
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, prod := range g.Productions {
+			for _, alt := range prod.Right {
+				if s.first[prod.Left].addAll(s.firstK(alt.Symbols)) {
+					changed = true
+				}
+			}
+		}
+	}
+
+	end := make([]string, k)
+	for i := range end {
+		end[i] = g.EndSymbol().Value
+	}
+	s.follow[g.Start].add(end)
+	for changed := true; changed; {
+		changed = false
+		for _, prod := range g.Productions {
+			for _, alt := range prod.Right {
+				for i, sym := range alt.Symbols {
+					if _, ok := s.follow[sym]; !ok || sym.IsTerminal {
+						continue
+					}
+					rest := concatK(s.firstK(alt.Symbols[i+1:]), s.follow[prod.Left], k)
+					if s.follow[sym].addAll(rest) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return s
+}
+
+// FirstK
+// 返回每个非终结符的FIRST_k集，每个元素是一个长度不超过k的终结符序列，短于k的序列表示推导到此结束
+func (g *Grammar) FirstK(k int) map[Symbol][][]string {
+	result := make(map[Symbol][][]string)
+	for nt, set := range g.computeLLkSets(k).first {
+		result[nt] = set.seqs
+	}
+	return result
+}
+
+// FollowK
+// 返回每个非终结符的FOLLOW_k集，输入末尾用结束符补足k个符号
+func (g *Grammar) FollowK(k int) map[Symbol][][]string {
+	result := make(map[Symbol][][]string)
+	for nt, set := range g.computeLLkSets(k).follow {
+		result[nt] = set.seqs
+	}
+	return result
+}
+
+// LLkConflict
+// 同一个非终结符的两个备选项在相同的向前看串下都可以选择
+type LLkConflict struct {
+	NonTerminal Symbol
+	First       Alternative
+	Second      Alternative
+	Lookahead   [][]string
+}
+
+func (c LLkConflict) String() string {
+	lookahead := make([]string, len(c.Lookahead))
+	for i, seq := range c.Lookahead {
+		lookahead[i] = JoinSymbols(seq)
+	}
+	return fmt.Sprintf("LL(k) conflict on %s: %s -> %s and %s -> %s share lookahead {%s}",
+		c.NonTerminal.Value,
+		c.NonTerminal.Value, SymbolsToString(c.First.Symbols),
+		c.NonTerminal.Value, SymbolsToString(c.Second.Symbols),
+		strings.Join(lookahead, ", "))
+}
+
+// LLkTable
+// LL(k)预测分析表，k个终结符的向前看串决定使用的产生式
+type LLkTable struct {
+	K     int
+	Start Symbol
+	// End 文法的输入结束符，向前看串中用它的Value表示
+	End     Symbol
+	entries map[Symbol]map[string]Production
+	// lookaheads 每个非终结符有产生式的向前看串，按加入的顺序
+	lookaheads map[Symbol][][]string
+}
+
+// Lookup
+// 查找 M[nt, lookahead]，lookahead的长度必须为K
+func (t *LLkTable) Lookup(nt Symbol, lookahead []string) (Production, bool) {
+	prod, ok := t.entries[nt][seqKey(lookahead)]
+	return prod, ok
+}
+
+// Lookaheads
+// 按顺序返回非终结符所在行中有产生式的向前看串
+func (t *LLkTable) Lookaheads(nt Symbol) [][]string {
+	return t.lookaheads[nt]
+}
+
+// BuildLLk
+// 构造LL(k)预测分析表，有冲突时仍然返回表（冲突的表项保留先出现的备选项）和所有冲突。
+// 需要先调用MarkTerminals，通常在GInit之后调用，使用消除左递归、提取左公因子之后的文法
+func (g *Grammar) BuildLLk(k int) (*LLkTable, []LLkConflict) {
+	sets := g.computeLLkSets(k)
+	t := &LLkTable{
+		K:          k,
+		Start:      g.Start,
+		End:        g.EndSymbol(),
+		entries:    make(map[Symbol]map[string]Production),
+		lookaheads: make(map[Symbol][][]string),
+	}
+	var conflicts []LLkConflict
+	for _, prod := range g.Productions {
+		if t.entries[prod.Left] == nil {
+			t.entries[prod.Left] = make(map[string]Production)
+		}
+		// 在同一个非终结符的备选项之间检查冲突
+		owner := make(map[string]int)
+		las := make([]*seqSet, len(prod.Right))
+		for i, alt := range prod.Right {
+			las[i] = concatK(sets.firstK(alt.Symbols), sets.follow[prod.Left], k)
+			for _, seq := range las[i].seqs {
+				key := seqKey(seq)
+				if j, ok := owner[key]; ok && j != i {
+					continue
+				}
+				owner[key] = i
+				if _, exists := t.entries[prod.Left][key]; exists {
+					// 左部相同的另一条产生式已经占用了这个表项
+					continue
+				}
+				t.entries[prod.Left][key] = Production{Left: prod.Left, Right: []Alternative{alt}}
+				t.lookaheads[prod.Left] = append(t.lookaheads[prod.Left], seq)
+			}
+		}
+		for i := 0; i < len(prod.Right); i++ {
+			for j := i + 1; j < len(prod.Right); j++ {
+				var overlap [][]string
+				for _, seq := range las[i].seqs {
+					if las[j].index[seqKey(seq)] {
+						overlap = append(overlap, seq)
+					}
+				}
+				if len(overlap) > 0 {
+					conflicts = append(conflicts, LLkConflict{
+						NonTerminal: prod.Left,
+						First:       prod.Right[i],
+						Second:      prod.Right[j],
+						Lookahead:   overlap,
+					})
+				}
+			}
+		}
+	}
+	return t, conflicts
+}
+
+// KReport
+// MinimalK的结果：Deterministic为true时K是使文法确定的最小k，否则K是上限，Conflicts是k为上限时的冲突
+type KReport struct {
+	K             int
+	Deterministic bool
+	Table         *LLkTable
+	Conflicts     []LLkConflict
+}
+
+// MinimalK
+// 从k=1开始依次构造LL(k)分析表，返回第一个没有冲突的k，最多尝试到limit
+func (g *Grammar) MinimalK(limit int) KReport {
+	var report KReport
+	for k := 1; k <= limit; k++ {
+		table, conflicts := g.BuildLLk(k)
+		report = KReport{K: k, Deterministic: len(conflicts) == 0, Table: table, Conflicts: conflicts}
+		if report.Deterministic {
+			break
+		}
+	}
+	return report
+}
+
+// Parse
+// 用LL(k)分析表分析单词序列，每次读取k个单词作为向前看串，遇到第一个错误时停止。
+// 返回的分析过程和分析树与Grammar.Parse相同
+func (t *LLkTable) Parse(tokens []Token) ParseResult {
+	result := ParseResult{}
+	end := endToken(tokens, t.End)
+	input := append([]Token(nil), tokens...)
+	for i := 0; i < t.K; i++ {
+		input = append(input, end)
+	}
+	root := &Node{Symbol: t.Start}
+	analysisStack := []Symbol{t.End, t.Start}
+	nodeStack := []*Node{nil, root}
+	pos := 0
+	for count := 1; ; count++ {
+		top := analysisStack[len(analysisStack)-1]
+		step := Step{
+			Number:        count,
+			AnalysisStack: append([]Symbol(nil), analysisStack...),
+			Input:         append([]Token(nil), input[pos:len(input)-t.K+1]...),
+		}
+		lookahead := make([]string, t.K)
+		for i := range lookahead {
+			lookahead[i] = input[pos+i].Kind
+		}
+		var prod Production
+		exist := false
+		switch {
+		case top.IsEnd() && lookahead[0] == t.End.Value:
+			step.Action = ActionAccept
+			result.Steps = append(result.Steps, step)
+			result.Accepted = true
+			result.Tree = root
+			return result
+		case top.IsTerminal:
+			exist = top.Value == lookahead[0]
+		default:
+			prod, exist = t.Lookup(top, lookahead)
+		}
+		if !exist {
+			step.Action = ActionError
+			offset, expected := t.expected(top, lookahead)
+			err := ParseError{Token: input[pos+offset], Expected: expected, End: pos+offset >= len(tokens)}
+			step.Error = &err
+			result.Errors = append(result.Errors, err)
+			result.Steps = append(result.Steps, step)
+			return result
+		}
+		node := nodeStack[len(nodeStack)-1]
+		analysisStack = analysisStack[:len(analysisStack)-1]
+		nodeStack = nodeStack[:len(nodeStack)-1]
+		if top.IsTerminal {
+			step.Action = ActionMatch
+			node.Token = input[pos]
+			pos++
+		} else {
+			step.Action = ActionExpand
+			step.Production = prod
+			symbols := prod.Right[0].Symbols
+			node.Children = make([]*Node, len(symbols))
+			for i, s := range symbols {
+				node.Children[i] = &Node{Symbol: s}
+			}
+			for i := len(symbols) - 1; i >= 0; i-- {
+				if !symbols[i].IsEpsilon() {
+					analysisStack = append(analysisStack, symbols[i])
+					nodeStack = append(nodeStack, node.Children[i])
+				}
+			}
+		}
+		result.Steps = append(result.Steps, step)
+	}
+}
+
+// expected
+// 出错时找到向前看串中第一个无法匹配的单词，返回它相对当前位置的偏移和在该位置可以接受的终结符
+func (t *LLkTable) expected(top Symbol, lookahead []string) (int, []Symbol) {
+	if top.IsTerminal || top.IsEnd() {
+		return 0, []Symbol{top}
+	}
+	candidates := t.lookaheads[top]
+	for i := range lookahead {
+		set := NewSymbolSet()
+		var matched [][]string
+		for _, seq := range candidates {
+			if seq[i] == t.End.Value {
+				set.Add(t.End)
+			} else {
+				set.Add(Symbol{Value: seq[i], IsTerminal: true})
+			}
+			if seq[i] == lookahead[i] {
+				matched = append(matched, seq)
+			}
+		}
+		if len(matched) == 0 {
+			return i, set.Symbols()
+		}
+		candidates = matched
+	}
+	return 0, nil
+}
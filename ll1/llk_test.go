@@ -0,0 +1,187 @@
+package ll1
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// llkGrammar
+// 读入文法并调用GInit，文法可以不是LL(1)文法
+func llkGrammar(t *testing.T, src string) *Grammar {
+	t.Helper()
+	g, err := ParseGrammar(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// seqStrings
+// 把终结符序列写成 "a b" 的形式并排序，便于比较
+func seqStrings(seqs [][]string) string {
+	result := make([]string, len(seqs))
+	for i, seq := range seqs {
+		result[i] = strings.Join(seq, " ")
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+func TestMinimalK(t *testing.T) {
+	tests := []struct {
+		name          string
+		src           string
+		limit         int
+		k             int
+		deterministic bool
+	}{
+		{"LL(1)", "S -> a S | b ;", 3, 1, true},
+		{"LL(2)", "S -> A b | B c ; A -> a ; B -> a ;", 3, 2, true},
+		{"LL(3)", "S -> A b b | B b c ; A -> a ; B -> a ;", 3, 3, true},
+		{"LL(3) over the limit", "S -> A b b | B b c ; A -> a ; B -> a ;", 2, 2, false},
+		{"not LL(k)", "S -> A | B ; A -> a A | c ; B -> a B | d ;", 4, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := llkGrammar(t, tt.src)
+			report := g.MinimalK(tt.limit)
+			if report.K != tt.k || report.Deterministic != tt.deterministic {
+				t.Fatalf("MinimalK(%d) = k %d, deterministic %v, want k %d, %v", tt.limit, report.K, report.Deterministic, tt.k, tt.deterministic)
+			}
+			if tt.deterministic != (len(report.Conflicts) == 0) {
+				t.Errorf("conflicts = %v", report.Conflicts)
+			}
+			if report.Table == nil || report.Table.K != report.K {
+				t.Errorf("table = %+v, want k %d", report.Table, report.K)
+			}
+		})
+	}
+}
+
+func TestMinimalKConflicts(t *testing.T) {
+	g := llkGrammar(t, "S -> A | B ; A -> a A | c ; B -> a B | d ;")
+	report := g.MinimalK(2)
+	if len(report.Conflicts) != 1 {
+		t.Fatalf("conflicts = %v, want one", report.Conflicts)
+	}
+	c := report.Conflicts[0]
+	if c.NonTerminal.Value != "S" || SymbolsToString(c.First.Symbols) != "A" || SymbolsToString(c.Second.Symbols) != "B" {
+		t.Errorf("conflict = %v", c)
+	}
+	if got := seqStrings(c.Lookahead); got != "a a" {
+		t.Errorf("conflict lookahead = %s, want a a", got)
+	}
+}
+
+func TestFirstKFollowK(t *testing.T) {
+	g := llkGrammar(t, "S -> A b | B c | ; A -> a ; B -> a a ;")
+	first := g.FirstK(2)
+	follow := g.FollowK(2)
+	tests := []struct {
+		nt            string
+		first, follow string
+	}{
+		{"S", ", a a, a b", "# #"},
+		{"A", "a", "b #"},
+		{"B", "a a", "c #"},
+	}
+	for _, tt := range tests {
+		nt := Symbol{Value: tt.nt}
+		if got := seqStrings(first[nt]); got != tt.first {
+			t.Errorf("FIRST_2(%s) = {%s}, want {%s}", tt.nt, got, tt.first)
+		}
+		if got := seqStrings(follow[nt]); got != tt.follow {
+			t.Errorf("FOLLOW_2(%s) = {%s}, want {%s}", tt.nt, got, tt.follow)
+		}
+	}
+}
+
+// TestBuildLLkMatchesPredict
+// 没有冲突时k=1的表与InitializePredict的结果相同
+func TestBuildLLkMatchesPredict(t *testing.T) {
+	g := llkGrammar(t, "E -> E '+' T | T ; T -> '(' E ')' | id ;")
+	table, conflicts := g.BuildLLk(1)
+	if len(conflicts) > 0 {
+		t.Fatalf("conflicts = %v", conflicts)
+	}
+	for nt, row := range g.Predict {
+		if got, want := len(table.Lookaheads(nt)), len(row); got != want {
+			t.Errorf("row %s has %d entries, want %d", nt.Value, got, want)
+		}
+		for lookahead, want := range row {
+			got, ok := table.Lookup(nt, []string{lookahead.Value})
+			if !ok || got.String() != want.String() {
+				t.Errorf("M[%s,%s] = %v, want %v", nt.Value, lookahead.Value, got, want)
+			}
+		}
+	}
+}
+
+// wordTokens
+// 按空格切分输入，每个词是一个单词，Kind就是词本身，输入中可以有文法之外的终结符
+func wordTokens(input string) []Token {
+	var tokens []Token
+	column := 1
+	for _, word := range strings.Split(input, " ") {
+		tokens = append(tokens, Token{Kind: word, Text: word, Pos: Position{Offset: column - 1, Line: 1, Column: column}})
+		column += len(word) + 1
+	}
+	return tokens
+}
+
+func TestLLkParse(t *testing.T) {
+	g := llkGrammar(t, "S -> A b | B c ; A -> a ; B -> a ;")
+	table, conflicts := g.BuildLLk(2)
+	if len(conflicts) > 0 {
+		t.Fatalf("conflicts = %v", conflicts)
+	}
+	tests := []struct {
+		input    string
+		accepted bool
+		// 出错的单词、是否在输入末尾和可以接受的终结符
+		errText  string
+		errEnd   bool
+		expected string
+		column   int
+	}{
+		{input: "a b", accepted: true},
+		{input: "a c", accepted: true},
+		{input: "a d", errText: "d", expected: "bc", column: 3},
+		{input: "d", errText: "d", expected: "a", column: 1},
+		{input: "a", errEnd: true, expected: "bc", column: 2},
+		{input: "a b b", errText: "b", expected: "#", column: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := table.Parse(wordTokens(tt.input))
+			if result.Accepted != tt.accepted {
+				t.Fatalf("Accepted = %v, want %v; errors %v", result.Accepted, tt.accepted, result.Errors)
+			}
+			if tt.accepted {
+				if result.Tree == nil || len(result.Errors) > 0 {
+					t.Errorf("tree = %v, errors = %v", result.Tree, result.Errors)
+				}
+				last := result.Steps[len(result.Steps)-1]
+				if last.Action != ActionAccept {
+					t.Errorf("last step = %v, want accept", last.Action)
+				}
+				return
+			}
+			if len(result.Errors) != 1 {
+				t.Fatalf("errors = %v, want one", result.Errors)
+			}
+			e := result.Errors[0]
+			if e.End != tt.errEnd || !tt.errEnd && e.Token.Text != tt.errText || e.Token.Pos.Column != tt.column {
+				t.Errorf("error at %q (end %v, column %d), want %q (end %v, column %d)",
+					e.Token.Text, e.End, e.Token.Pos.Column, tt.errText, tt.errEnd, tt.column)
+			}
+			if got := SymbolsToString(e.Expected); got != tt.expected {
+				t.Errorf("expected = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package ll1
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// 文法文件格式
+//
+//	// 行注释，/* 块注释 */
+//	%start expr            // 开始符，省略时为第一条产生式的左部
+//	%token id num          // 声明终结符，声明过的标识符不能作为产生式左部
+//	%token num /[0-9]+/    // 声明终结符并绑定正则表达式，输入中匹配的内容都是num
+//	%token kw_if "if"      // 声明终结符并绑定原文
+//	%skip /\s+/ /#[^\n]*/  // 词法分析时跳过的内容，省略时跳过空白
+//	%prefer S_1 e -> e S   // 冲突时S_1在向前看符号为e时选择 e S，名字指改写后的文法
+//	%end '$'               // 输入结束符，省略时为#，不能在产生式中使用
+//	expr      -> term expr_tail ;
+//	expr_tail -> '+' term expr_tail
+//	           | ε
+//
+// 产生式中的符号使用与ParseProduction相同的记法，可以使用EBNF运算符；产生式可以跨行书写，
+// 以 ; 结束，或在下一条产生式的左部（后面跟着 -> 的标识符）出现时结束。
+
+// ParseGrammar
+// 按文法文件格式解析src，返回未初始化的文法，错误带有行号和列号
+func ParseGrammar(src string) (*Grammar, error) {
+	tokens, err := scanNotation(src, 1)
+	if err != nil {
+		return nil, err
+	}
+	p := newNotationParser(tokens)
+	var start, end *notationToken
+	var prods []Production
+	var prefs []Preference
+	declared := make(map[string]notationToken)
+	lefts := make(map[string]notationToken)
+	lexer := NewLexer()
+	customLexer, skips := false, false
+	for p.peek().kind != notationEOF {
+		tok := p.peek()
+		switch tok.kind {
+		case notationDirective:
+			p.next()
+			switch tok.text {
+			case "start":
+				sym := p.next()
+				if sym.kind != notationIdent && sym.kind != notationNonTerminal {
+					return nil, p.errorf(sym, "expected nonterminal after %%start, found %q", sym.text)
+				}
+				if start != nil {
+					return nil, p.errorf(tok, "duplicate %%start, already declared at line %d", start.line)
+				}
+				start = &sym
+			case "token":
+				n := 0
+				for p.isSymbol() && !p.startsProduction() {
+					sym := p.next()
+					if sym.kind == notationNonTerminal {
+						return nil, p.errorf(sym, "%%token cannot declare nonterminal <%s>", sym.text)
+					}
+					declared[sym.text] = sym
+					n++
+					// 标识符后面可以跟着绑定的正则表达式或原文
+					if bind := p.peek(); sym.kind == notationIdent && (bind.kind == notationPattern || bind.kind == notationTerminal) {
+						p.next()
+						if bind.kind == notationPattern {
+							if err := lexer.Pattern(sym.text, bind.text); err != nil {
+								return nil, p.errorf(bind, "%v", err)
+							}
+						} else {
+							lexer.Literal(sym.text, bind.text)
+						}
+						customLexer = true
+					}
+				}
+				if n == 0 {
+					return nil, p.errorf(p.peek(), "expected terminal names after %%token")
+				}
+			case "skip":
+				if p.peek().kind != notationPattern {
+					return nil, p.errorf(p.peek(), "expected /pattern/ after %%skip")
+				}
+				for p.peek().kind == notationPattern {
+					pattern := p.next()
+					if err := lexer.Skip(pattern.text); err != nil {
+						return nil, p.errorf(pattern, "%v", err)
+					}
+					skips = true
+				}
+			case "end":
+				sym := p.next()
+				if sym.kind != notationIdent && sym.kind != notationTerminal || isEpsilonToken(sym) {
+					return nil, p.errorf(sym, "expected end marker after %%end, found %q", sym.text)
+				}
+				if end != nil {
+					return nil, p.errorf(tok, "duplicate %%end, already declared at line %d", end.line)
+				}
+				end = &sym
+			case "prefer":
+				pref, err := p.preferences(tok)
+				if err != nil {
+					return nil, err
+				}
+				prefs = append(prefs, pref...)
+			default:
+				return nil, p.errorf(tok, "unknown directive %%%s", tok.text)
+			}
+		case notationSemicolon:
+			p.next()
+		default:
+			prod, err := p.production()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := lefts[prod.Left.Value]; !ok {
+				lefts[prod.Left.Value] = tok
+			}
+			prods = append(prods, prod)
+			// EBNF运算符展开得到的辅助产生式紧跟在所在产生式之后
+			prods = append(prods, p.helpers...)
+			p.helpers = nil
+			if next := p.peek(); next.kind != notationSemicolon && next.kind != notationEOF &&
+				next.kind != notationDirective && !p.startsProduction() {
+				return nil, p.errorf(next, "unexpected %q after production", next.text)
+			}
+		}
+	}
+	if len(prods) == 0 {
+		return nil, &SyntaxError{Line: p.peek().line, Column: p.peek().column, Msg: "grammar has no productions"}
+	}
+	// %token 声明的符号一定是终结符
+	for name, tok := range declared {
+		if left, ok := lefts[name]; ok {
+			return nil, p.errorf(left, "%s is declared as a terminal at line %d but has a production", name, tok.line)
+		}
+	}
+	for i := range prods {
+		for j := range prods[i].Right {
+			for k, sym := range prods[i].Right[j].Symbols {
+				if _, ok := declared[sym.Value]; ok {
+					prods[i].Right[j].Symbols[k].IsTerminal = true
+				}
+			}
+		}
+	}
+	startSymbol := prods[0].Left
+	if start != nil {
+		startSymbol = Symbol{Value: start.text, IsTerminal: false}
+	}
+	g := NewGrammar(startSymbol, prods)
+	g.Preferences = prefs
+	if end != nil {
+		g.End = end.text
+	}
+	g.Declared = make(map[string]bool)
+	for _, tok := range p.tokens {
+		if tok.kind == notationNonTerminal {
+			g.Declared[tok.text] = false
+		}
+	}
+	for name := range declared {
+		g.Declared[name] = true
+	}
+	if customLexer || skips {
+		if !skips {
+			lexer.Skip(`\s+`)
+		}
+		g.Lexer = lexer
+	}
+	return g, nil
+}
+
+// LoadGrammar
+// 从r中读取文法文件并解析
+func LoadGrammar(r io.Reader) (*Grammar, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGrammar(string(src))
+}
+
+// LoadGrammarFile
+// 读取并解析文法文件，错误信息中带有文件名
+func LoadGrammarFile(path string) (*Grammar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g, err := LoadGrammar(f)
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		syntaxErr.File = path
+	}
+	return g, err
+}
+
+// preferences
+// 解析 %prefer 非终结符 向前看符号... -> 备选项，每个向前看符号得到一条Preference
+func (p *notationParser) preferences(directive notationToken) ([]Preference, error) {
+	nt := p.next()
+	if nt.kind != notationIdent && nt.kind != notationNonTerminal || isEpsilonToken(nt) {
+		return nil, p.errorf(nt, "expected nonterminal after %%prefer, found %q", nt.text)
+	}
+	var lookahead []Symbol
+	for p.isSymbol() {
+		tok := p.next()
+		if tok.kind == notationNonTerminal || isEpsilonToken(tok) {
+			return nil, p.errorf(tok, "lookahead of %%prefer must be a terminal, found %q", tok.text)
+		}
+		lookahead = append(lookahead, Symbol{Value: tok.text, IsTerminal: true})
+	}
+	if len(lookahead) == 0 {
+		return nil, p.errorf(p.peek(), "expected lookahead terminals after %%prefer %s", nt.text)
+	}
+	if arrow := p.next(); arrow.kind != notationArrow {
+		return nil, p.errorf(arrow, "expected -> in %%prefer, found %q", arrow.text)
+	}
+	alt, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	prefs := make([]Preference, len(lookahead))
+	for i, s := range lookahead {
+		prefs[i] = Preference{
+			NonTerminal: Symbol{Value: nt.text, IsTerminal: false},
+			Lookahead:   s,
+			Alternative: alt,
+			Line:        directive.line,
+		}
+	}
+	return prefs, nil
+}
+
+// isSymbol
+// 当前记号是否可以作为符号
+func (p *notationParser) isSymbol() bool {
+	switch p.peek().kind {
+	case notationIdent, notationNonTerminal, notationTerminal:
+		return true
+	}
+	return false
+}
+
+// startsProduction
+// 当前记号是否为一条新产生式的左部，即后面紧跟着 ->
+func (p *notationParser) startsProduction() bool {
+	return p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].kind == notationArrow
+}
@@ -0,0 +1,519 @@
+package ll1
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 文法记法
+//
+//	expr      -> term expr_tail
+//	expr_tail -> '+' term expr_tail | ε
+//	factor    -> '(' expr ')' | <id> | "num"
+//
+// 符号之间用空白分隔；标识符由字母、数字、下划线和'组成，不以数字开头；
+// 引号括起来的 '+'、"while" 一定是终结符；尖括号括起来的 <expr> 一定是非终结符；
+// 其余标识符若作为某个产生式的左部出现则是非终结符，否则在MarkTerminals时被视为终结符；
+// ε、eps和空的备选项（如 A -> a | ）表示空串。
+// 产生式右部还可以使用EBNF的 ( ) [ ] { } 和后缀 * + ?，见ebnf.go；::= 和 = 可以代替 -> 。
+
+// SyntaxError
+// 文法记法中的语法错误，Line和Column从1开始计数，Line为0表示单行输入，File为空表示不是从文件读入
+type SyntaxError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	pos := fmt.Sprintf("column %d", e.Column)
+	if e.Line > 0 {
+		pos = fmt.Sprintf("%d:%d", e.Line, e.Column)
+	}
+	if e.File != "" {
+		pos = e.File + ":" + pos
+	}
+	return pos + ": " + e.Msg
+}
+
+type notationKind int
+
+const (
+	notationIdent notationKind = iota
+	notationNonTerminal
+	notationTerminal
+	notationArrow
+	notationBar
+	notationSemicolon
+	notationDirective
+	notationPattern
+	// notationOpen ( [ {
+	notationOpen
+	// notationClose ) ] }
+	notationClose
+	// notationRepeat 后缀 * + ?
+	notationRepeat
+	notationEOF
+)
+
+type notationToken struct {
+	kind   notationKind
+	text   string
+	line   int
+	column int
+}
+
+// scanNotation
+// 将一段文法记法切分为记号，跳过 // 行注释和 /* */ 块注释。
+// line为这段文本第一行的行号，用于错误定位；为0时表示单行输入，不再计算行号
+func scanNotation(src string, line int) ([]notationToken, error) {
+	var tokens []notationToken
+	column := 1
+	for len(src) > 0 {
+		r, size := utf8.DecodeRuneInString(src)
+		start := column
+		switch {
+		case r == '\n':
+			src = src[size:]
+			if line > 0 {
+				line++
+			}
+			column = 1
+		case unicode.IsSpace(r):
+			src = src[size:]
+			column++
+		case strings.HasPrefix(src, "//"):
+			end := strings.IndexByte(src, '\n')
+			if end < 0 {
+				end = len(src)
+			}
+			column += utf8.RuneCountInString(src[:end])
+			src = src[end:]
+		case strings.HasPrefix(src, "/*"):
+			end := strings.Index(src, "*/")
+			if end < 0 {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "unterminated comment"}
+			}
+			comment := src[:end+2]
+			if n := strings.Count(comment, "\n"); n > 0 {
+				if line > 0 {
+					line += n
+				}
+				column = utf8.RuneCountInString(comment[strings.LastIndexByte(comment, '\n')+1:]) + 1
+			} else {
+				column += utf8.RuneCountInString(comment)
+			}
+			src = src[end+2:]
+		case r == '/':
+			text, n, ok := scanPattern(src)
+			if !ok {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "unterminated pattern"}
+			}
+			tokens = append(tokens, notationToken{notationPattern, text, line, start})
+			column += utf8.RuneCountInString(src[:n])
+			src = src[n:]
+		case strings.HasPrefix(src, "->"):
+			tokens = append(tokens, notationToken{notationArrow, "->", line, start})
+			src = src[2:]
+			column += 2
+		case strings.HasPrefix(src, "::="):
+			tokens = append(tokens, notationToken{notationArrow, "::=", line, start})
+			src = src[3:]
+			column += 3
+		case r == '=':
+			tokens = append(tokens, notationToken{notationArrow, "=", line, start})
+			src = src[size:]
+			column++
+		case strings.ContainsRune("([{", r):
+			tokens = append(tokens, notationToken{notationOpen, string(r), line, start})
+			src = src[size:]
+			column++
+		case strings.ContainsRune(")]}", r):
+			tokens = append(tokens, notationToken{notationClose, string(r), line, start})
+			src = src[size:]
+			column++
+		case strings.ContainsRune("*+?", r):
+			tokens = append(tokens, notationToken{notationRepeat, string(r), line, start})
+			src = src[size:]
+			column++
+		case r == '|':
+			tokens = append(tokens, notationToken{notationBar, "|", line, start})
+			src = src[size:]
+			column++
+		case r == ';':
+			tokens = append(tokens, notationToken{notationSemicolon, ";", line, start})
+			src = src[size:]
+			column++
+		case r == '%':
+			n := identLength(src[size:])
+			if n == 0 {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "expected directive name after '%'"}
+			}
+			tokens = append(tokens, notationToken{notationDirective, src[size : size+n], line, start})
+			column += 1 + utf8.RuneCountInString(src[size:size+n])
+			src = src[size+n:]
+		case r == '\'' || r == '"':
+			text, n, ok := scanQuoted(src)
+			if !ok {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "unterminated quoted terminal"}
+			}
+			if text == "" {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "empty quoted terminal"}
+			}
+			tokens = append(tokens, notationToken{notationTerminal, text, line, start})
+			column += utf8.RuneCountInString(src[:n])
+			src = src[n:]
+		case r == '<':
+			end := strings.IndexByte(src, '>')
+			if end < 0 {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: "missing '>' after nonterminal"}
+			}
+			name := src[1:end]
+			if !IsIdentifier(name) {
+				return nil, &SyntaxError{Line: line, Column: start, Msg: fmt.Sprintf("invalid nonterminal name <%s>", name)}
+			}
+			tokens = append(tokens, notationToken{notationNonTerminal, name, line, start})
+			column += utf8.RuneCountInString(src[:end+1])
+			src = src[end+1:]
+		case isIdentStart(r):
+			n := identLength(src)
+			tokens = append(tokens, notationToken{notationIdent, src[:n], line, start})
+			column += utf8.RuneCountInString(src[:n])
+			src = src[n:]
+		default:
+			return nil, &SyntaxError{Line: line, Column: start, Msg: fmt.Sprintf("unexpected character %q, quote terminals such as '%c'", r, r)}
+		}
+	}
+	tokens = append(tokens, notationToken{notationEOF, "", line, column})
+	return tokens, nil
+}
+
+// identLength
+// 返回src开头标识符的字节长度，不以标识符开头时返回0
+func identLength(src string) int {
+	n := 0
+	for n < len(src) {
+		c, s := utf8.DecodeRuneInString(src[n:])
+		if n == 0 && !isIdentStart(c) || !isIdentPart(c) {
+			break
+		}
+		n += s
+	}
+	return n
+}
+
+// quotedEscapes 引号终结符中表示控制字符的转义
+var quotedEscapes = map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '0': 0}
+
+// scanQuoted
+// 读取一个以 ' 或 " 括起来的终结符，支持 \n \t \r \0 表示控制字符，
+// 其他字符前的反斜杠表示字符本身（如 \' \" \\），返回内容和消耗的字节数
+func scanQuoted(src string) (string, int, bool) {
+	quote := src[0]
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				if c, ok := quotedEscapes[src[i]]; ok {
+					sb.WriteByte(c)
+				} else {
+					sb.WriteByte(src[i])
+				}
+			}
+		case quote:
+			return sb.String(), i + 1, true
+		case '\n':
+			return "", 0, false
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", 0, false
+}
+
+// QuoteTerminal
+// 把终结符写成用quote（' 或 "）括起来的记法，是scanQuoted的逆运算：
+// 反斜杠和引号前加反斜杠，换行、制表符、回车和NUL写成 \n \t \r \0
+func QuoteTerminal(text string, quote byte) string {
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case 0:
+			sb.WriteString(`\0`)
+		case '\\', quote:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
+
+// scanPattern
+// 读取一个以 / 括起来的正则表达式，\/ 表示 /，其余转义原样保留，返回内容和消耗的字节数
+func scanPattern(src string) (string, int, bool) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) && src[i+1] == '/' {
+				i++
+				sb.WriteByte('/')
+			} else if i+1 < len(src) {
+				sb.WriteByte(src[i])
+				i++
+				sb.WriteByte(src[i])
+			}
+		case '/':
+			return sb.String(), i + 1, sb.Len() > 0
+		case '\n':
+			return "", 0, false
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", 0, false
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '\''
+}
+
+// IsIdentifier
+// 判断字符串是否可以作为记法中的标识符
+func IsIdentifier(s string) bool {
+	for i, r := range s {
+		if i == 0 && !isIdentStart(r) || !isIdentPart(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ParseProduction
+// 按文法记法解析一行产生式，例如 expr -> term expr_tail | ε；
+// 使用了EBNF运算符、需要辅助非终结符时返回错误，应使用ParseProductions
+func ParseProduction(line string) (Production, error) {
+	prods, err := ParseProductions(line)
+	if err != nil {
+		return Production{}, err
+	}
+	if len(prods) > 1 {
+		return Production{}, &SyntaxError{Column: prods[1].Pos.Column, Msg: "EBNF operators need helper nonterminals, use ParseProductions"}
+	}
+	return prods[0], nil
+}
+
+// ParseProductions
+// 按文法记法解析一行产生式，第一个是这一行的产生式，其后是展开EBNF运算符得到的辅助产生式，
+// 例如 expr -> term { '+' term } 得到 expr -> term expr_rep1 和 expr_rep1 -> '+' term expr_rep1 | ε
+func ParseProductions(line string) ([]Production, error) {
+	tokens, err := scanNotation(line, 0)
+	if err != nil {
+		return nil, err
+	}
+	p := newNotationParser(tokens)
+	prod, err := p.production()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != notationEOF {
+		return nil, p.errorf(tok, "unexpected %q after production", tok.text)
+	}
+	return append([]Production{prod}, p.helpers...), nil
+}
+
+// ParseSymbol
+// 按文法记法解析单个符号，例如开始符 expr
+func ParseSymbol(s string) (Symbol, error) {
+	tokens, err := scanNotation(s, 0)
+	if err != nil {
+		return Symbol{}, err
+	}
+	if len(tokens) != 2 {
+		return Symbol{}, &SyntaxError{Column: 1, Msg: fmt.Sprintf("%q is not a single symbol", s)}
+	}
+	return tokenSymbol(tokens[0])
+}
+
+// ParseCompactProduction
+// 按单字符记法解析一行产生式，例如 S->AaS|BbS|d，每个字符都是一个符号，ε和空的备选项表示空串
+func ParseCompactProduction(line string) (Production, error) {
+	parts := strings.Split(line, "->")
+	if len(parts) != 2 {
+		return Production{}, &SyntaxError{Column: 1, Msg: "production must contain exactly one '->'"}
+	}
+	left := strings.TrimSpace(parts[0])
+	if utf8.RuneCountInString(left) != 1 {
+		return Production{}, &SyntaxError{Column: 1, Msg: "left side must be a single character"}
+	}
+	rightPorts := strings.Split(parts[1], "|")
+
+	alternatives := make([]Alternative, len(rightPorts))
+	// column 当前备选项在行中的列号，从->之后开始
+	column := utf8.RuneCountInString(parts[0]) + 3
+	for i, rightStr := range rightPorts {
+		trimmedRightStr := strings.TrimSpace(rightStr)
+		symbols := []Symbol{}
+		for _, symbolRune := range trimmedRightStr {
+			// 假设文法输入时将所有符号视为非终结符
+			symbolStr := string(symbolRune)
+			if symbolStr == Epsilon.Value {
+				symbols = append(symbols, Epsilon)
+				continue
+			}
+			symbols = append(symbols, Symbol{Value: symbolStr, IsTerminal: false})
+		}
+		if len(symbols) == 0 {
+			symbols = append(symbols, Epsilon)
+		}
+		leading := utf8.RuneCountInString(rightStr) - utf8.RuneCountInString(strings.TrimLeftFunc(rightStr, unicode.IsSpace))
+		alternatives[i] = Alternative{Symbols: symbols, Pos: Position{Column: column + leading}}
+		column += utf8.RuneCountInString(rightStr) + 1
+	}
+	leftColumn := utf8.RuneCountInString(parts[0]) - utf8.RuneCountInString(strings.TrimLeftFunc(parts[0], unicode.IsSpace)) + 1
+	return Production{
+		Left:  Symbol{Value: left, IsTerminal: false},
+		Right: alternatives,
+		Pos:   Position{Column: leftColumn},
+	}, nil
+}
+
+type notationParser struct {
+	tokens []notationToken
+	pos    int
+	// left 正在解析的产生式的左部，不在产生式中时为空，此时不能使用EBNF运算符
+	left Symbol
+	// helpers 展开EBNF运算符得到的辅助产生式，names是已经使用的名字
+	helpers []Production
+	names   map[string]bool
+	counts  map[string]int
+	// nullable 可以推出ε的辅助非终结符
+	nullable map[string]bool
+}
+
+// newNotationParser
+// 记下所有标识符，辅助非终结符的名字不会与它们重复
+func newNotationParser(tokens []notationToken) *notationParser {
+	p := &notationParser{tokens: tokens, names: make(map[string]bool), counts: make(map[string]int), nullable: make(map[string]bool)}
+	for _, tok := range tokens {
+		switch tok.kind {
+		case notationIdent, notationNonTerminal, notationTerminal:
+			p.names[tok.text] = true
+		}
+	}
+	return p
+}
+
+func (p *notationParser) peek() notationToken {
+	return p.tokens[p.pos]
+}
+
+func (p *notationParser) next() notationToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != notationEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *notationParser) errorf(tok notationToken, format string, args ...interface{}) error {
+	return &SyntaxError{Line: tok.line, Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// production
+// production := nonterminal '->' alternative { '|' alternative }
+func (p *notationParser) production() (Production, error) {
+	leftTok := p.next()
+	if leftTok.kind != notationIdent && leftTok.kind != notationNonTerminal {
+		return Production{}, p.errorf(leftTok, "expected nonterminal on the left side, found %q", leftTok.text)
+	}
+	if isEpsilonToken(leftTok) {
+		return Production{}, p.errorf(leftTok, "%s cannot be the left side of a production", leftTok.text)
+	}
+	if tok := p.next(); tok.kind != notationArrow {
+		return Production{}, p.errorf(tok, "expected '->' after %s", leftTok.text)
+	}
+	prod := Production{
+		Left: Symbol{Value: leftTok.text, IsTerminal: false},
+		Pos:  Position{Line: leftTok.line, Column: leftTok.column},
+	}
+	p.left = prod.Left
+	defer func() { p.left = Symbol{} }()
+	for {
+		alt, err := p.alternative()
+		if err != nil {
+			return Production{}, err
+		}
+		prod.Right = append(prod.Right, alt)
+		if p.peek().kind != notationBar {
+			return prod, nil
+		}
+		p.next()
+	}
+}
+
+// alternative
+// alternative := item { item }
+func (p *notationParser) alternative() (Alternative, error) {
+	alt := Alternative{Pos: Position{Line: p.peek().line, Column: p.peek().column}}
+	for {
+		tok := p.peek()
+		// 下一个记号是 -> 说明当前标识符是下一条产生式的左部
+		if tok.kind != notationOpen && (!p.isSymbol() || p.startsProduction()) {
+			break
+		}
+		symbols, err := p.item()
+		if err != nil {
+			return Alternative{}, err
+		}
+		alt.Symbols = append(alt.Symbols, symbols...)
+	}
+	// 空的备选项就是ε
+	if len(alt.Symbols) == 0 {
+		alt.Symbols = []Symbol{Epsilon}
+	}
+	return alt, nil
+}
+
+// isEpsilonToken
+// 没有引号的ε和eps表示空串
+func isEpsilonToken(tok notationToken) bool {
+	return tok.kind == notationIdent && (tok.text == Epsilon.Value || tok.text == "eps")
+}
+
+func tokenSymbol(tok notationToken) (Symbol, error) {
+	switch tok.kind {
+	case notationIdent:
+		if isEpsilonToken(tok) {
+			return Epsilon, nil
+		}
+		return Symbol{Value: tok.text, IsTerminal: false}, nil
+	case notationNonTerminal:
+		return Symbol{Value: tok.text, IsTerminal: false}, nil
+	case notationTerminal:
+		if tok.text == Epsilon.Value {
+			return Symbol{}, &SyntaxError{Line: tok.line, Column: tok.column, Msg: "ε is reserved for the empty string and cannot be a terminal"}
+		}
+		return Symbol{Value: tok.text, IsTerminal: true}, nil
+	}
+	return Symbol{}, &SyntaxError{Line: tok.line, Column: tok.column, Msg: fmt.Sprintf("expected a symbol, found %q", tok.text)}
+}
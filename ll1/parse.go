@@ -0,0 +1,172 @@
+package ll1
+
+import "fmt"
+
+// ActionKind
+// 分析过程中每一步执行的动作
+type ActionKind int
+
+const (
+	// ActionMatch 分析栈顶终结符与输入匹配，两栈同时弹出
+	ActionMatch ActionKind = iota
+	// ActionExpand 用预测分析表中的产生式替换分析栈顶的非终结符
+	ActionExpand
+	// ActionAccept 两栈栈顶都为结束符且没有发现错误，分析成功
+	ActionAccept
+	// ActionError 两栈栈顶都为结束符但分析过程中发现了错误，分析结束
+	ActionError
+	// ActionSkip 错误恢复：跳过输入中的当前单词
+	ActionSkip
+	// ActionPop 错误恢复：弹出分析栈顶的符号
+	ActionPop
+)
+
+func (k ActionKind) String() string {
+	switch k {
+	case ActionMatch:
+		return "match"
+	case ActionExpand:
+		return "expand"
+	case ActionAccept:
+		return "accept"
+	case ActionError:
+		return "error"
+	case ActionSkip:
+		return "skip"
+	case ActionPop:
+		return "pop"
+	}
+	return fmt.Sprintf("ActionKind(%d)", int(k))
+}
+
+// Step
+// 分析过程中的一步：执行动作前的分析栈和剩余输入，以及执行的动作
+type Step struct {
+	Number        int
+	AnalysisStack []Symbol
+	Input         []Token
+	Action        ActionKind
+	// Production 为ActionExpand时使用的产生式
+	Production Production
+	// Error 这一步发现了新的错误时不为nil
+	Error *ParseError
+}
+
+// ParseResult
+// 分析的结果，Steps按顺序记录了整个分析过程，Errors按出现的顺序记录了所有语法错误
+type ParseResult struct {
+	Accepted bool
+	Steps    []Step
+	Errors   []ParseError
+	// Tree 分析成功时为分析树的根结点，否则为nil
+	Tree *Node
+}
+
+// Parse
+// 对词法分析得到的单词序列进行分析，输入串可以先用Tokenize切分，单词的Kind与终结符匹配
+// 分析栈和字符栈，懒得写个栈结构了，用切片将就吧，characterStack默认切片首元素为栈顶，尾元素为栈底。analysisStack默认切片首元素为栈底，尾元素为栈顶
+// 在循环中，检查分析栈顶的符号。如果它是一个终结符，请检查它是否与 characterStack 的栈顶元素匹配。如果匹配，则将两个栈的栈顶元素弹出；如果都为结束符，则分析结束
+// 如果栈顶符号是一个非终结符，请在预测分析表（g.Predict）中查找与当前非终结符和 characterStack 栈顶元素对应的产生式。将产生式右侧的符号逆序压入 analysisStack
+// 出错时按恐慌模式恢复，见recover
+func (g Grammar) Parse(tokens []Token) ParseResult {
+	result := ParseResult{}
+	//计数器，分析的步骤
+	count := 1
+	//分析栈和字符栈的初始化，输入的末尾是结束符
+	characterStack := append([]Token(nil), tokens...)
+	end := g.EndSymbol()
+	characterStack = append(characterStack, endToken(tokens, end))
+	var analysisStack []Symbol
+	analysisStack = append(analysisStack, end)
+	analysisStack = append(analysisStack, g.Start)
+	//与分析栈一一对应的分析树结点栈，结束符没有对应的结点
+	root := &Node{Symbol: g.Start}
+	nodeStack := []*Node{nil, root}
+	//正在恢复时不再重复报告错误，直到再次成功匹配或展开
+	recovering := false
+	for {
+		topAnalysis := analysisStack[len(analysisStack)-1]
+		topCharacter := characterStack[0].Kind
+		step := Step{
+			Number:        count,
+			AnalysisStack: append([]Symbol(nil), analysisStack...),
+			Input:         append([]Token(nil), characterStack...),
+		}
+		count++
+		if topAnalysis.IsEnd() && topCharacter == end.Value {
+			if len(result.Errors) == 0 {
+				step.Action = ActionAccept
+				result.Accepted = true
+				result.Tree = root
+			} else {
+				step.Action = ActionError
+			}
+			result.Steps = append(result.Steps, step)
+			return result
+		}
+		var prod Production
+		exist := false
+		if topAnalysis.IsTerminal {
+			exist = topAnalysis.Value == topCharacter
+		} else {
+			prod, exist = g.Predict[topAnalysis][g.lookahead(topCharacter)]
+		}
+		switch {
+		case !exist:
+			step.Action = g.recover(topAnalysis, topCharacter)
+			if !recovering {
+				err := ParseError{
+					Token:    characterStack[0],
+					Expected: g.expected(topAnalysis),
+					End:      topCharacter == end.Value,
+				}
+				result.Errors = append(result.Errors, err)
+				step.Error = &err
+				recovering = true
+			}
+			if step.Action == ActionSkip {
+				characterStack = characterStack[1:]
+			} else {
+				analysisStack = analysisStack[:len(analysisStack)-1]
+				nodeStack = nodeStack[:len(nodeStack)-1]
+			}
+		case topAnalysis.IsTerminal:
+			step.Action = ActionMatch
+			recovering = false
+			nodeStack[len(nodeStack)-1].Token = characterStack[0]
+			analysisStack = analysisStack[:len(analysisStack)-1]
+			nodeStack = nodeStack[:len(nodeStack)-1]
+			characterStack = characterStack[1:]
+		default:
+			step.Action = ActionExpand
+			step.Production = prod
+			recovering = false
+			node := nodeStack[len(nodeStack)-1]
+			analysisStack = analysisStack[:len(analysisStack)-1]
+			nodeStack = nodeStack[:len(nodeStack)-1]
+			symbols := prod.Right[0].Symbols
+			node.Children = make([]*Node, len(symbols))
+			for i, s := range symbols {
+				node.Children[i] = &Node{Symbol: s}
+			}
+			for i := len(symbols) - 1; i >= 0; i-- {
+				if !symbols[i].IsEpsilon() {
+					analysisStack = append(analysisStack, symbols[i])
+					nodeStack = append(nodeStack, node.Children[i])
+				}
+			}
+		}
+		result.Steps = append(result.Steps, step)
+	}
+}
+
+// endToken
+// 输入末尾的结束符，位置紧跟在最后一个单词之后
+func endToken(tokens []Token, end Symbol) Token {
+	pos := Position{Line: 1, Column: 1}
+	if len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		pos = advance(last.Pos, last.Text)
+	}
+	return Token{Kind: end.Value, Pos: pos}
+}
@@ -0,0 +1,102 @@
+package ll1
+
+// GInit
+// 对于一个输入了开始符和产生式集的文法进行初始化，得到他的Nullable，FirstSet，FollowSet，Predict，并判断是否为LL1文法，返回结果
+// 先用Validate检查文法，RemoveUseless为true时先化简文法；改写文法的每一步记录在Rewrites中；左递归无法消除或%prefer指向不存在的备选项时返回错误。
+// 文法的冲突都被%prefer解决时也会构造预测分析表并返回true，解决的冲突可以用Conflicts查看
+func (g *Grammar) GInit() (bool, error) {
+	if err := g.Validate(); err != nil {
+		return false, err
+	}
+	g.normalizeEpsilon()
+	g.Rewrites = nil
+	//需要时先删除无用的符号，未定义的符号在MarkTerminals之后会被当作终结符
+	if g.RemoveUseless {
+		rewrite, err := g.RemoveUselessSymbols()
+		if err != nil {
+			return false, err
+		}
+		g.Rewrites = append(g.Rewrites, rewrite)
+	}
+	//未定义的符号和推不出终结符串的开始符在MarkTerminals之后就看不出来了，先检查
+	if err := g.checkDefined(); err != nil {
+		return false, err
+	}
+	//更新符号的 IsTerminal 字段
+	g.MarkTerminals()
+	//先消除左递归再提取左公因子，代入产生式时可能产生新的公共前缀
+	rewrite, err := g.EliminateLeftRecursion()
+	if err != nil {
+		return false, err
+	}
+	g.Rewrites = append(g.Rewrites, rewrite)
+	g.Rewrites = append(g.Rewrites, g.ExtractCommonFactors())
+
+	//初始化nullable表，first表，follow表
+	g.InitializeNullable()
+	g.InitializeFirstSet()
+	g.InitializeFollowSet()
+	//%prefer只能用在冲突的表项上，检查需要select集
+	if err := g.checkPreferences(); err != nil {
+		return false, err
+	}
+	//如果是LL1文法或冲突都已解决则继续否则结束
+	if len(g.UnresolvedConflicts()) == 0 {
+		g.InitializePredict()
+		return true, nil
+	}
+	return false, nil
+}
+
+// InitializePredict
+// Predict     map[Symbol]map[Symbol]Production
+// 构造分析表的第一个元素为左边的非终结符，第二个元素为上面的终结符
+// 对文法G的每个产生式A->α 执行如下步骤：
+// （1）对每个a∈First(α)，把 A->α 加入M[A,a]
+// （2）若 ε∈First(α)，则对任何b∈Follow(A) ,把 A->α 加至M[A,b]中
+// 两步合起来就是对每个a∈Select(A->α)把 A->α 加入M[A,a]，表中的列只有终结符和结束符，没有ε。
+// 得到构造表Predict 存储了M[A,b]，最后用%prefer指定的备选项覆盖冲突的表项
+func (g *Grammar) InitializePredict() {
+	g.Predict = make(map[Symbol]map[Symbol]Production)
+	//初始化
+	for _, nonterminal := range g.GetNonTerminals() {
+		g.Predict[nonterminal] = make(map[Symbol]Production)
+	}
+	for _, prod := range g.Productions {
+		for _, alter := range prod.Right {
+			//对于每个产生式prod.left->alter
+			for _, s := range g.Select(prod.Left, alter.Symbols) {
+				g.Predict[prod.Left][s] = Production{
+					Left:  prod.Left,
+					Right: []Alternative{alter},
+				}
+			}
+		}
+	}
+	g.applyPreferences()
+}
+
+// IsLL1
+// 对于每个非终结符A，检查每对产生式P1, P2是否存在以下情况
+// 1. P1和P2都以相同的终结符开始，这是FIRST集冲突
+// 2. P1的FIRST集包含空串，P2的FIRST集与A的FOLLOW集有交集，这是FOLLOW集冲突
+// 具体的冲突可以用Conflicts得到
+func (g Grammar) IsLL1() bool {
+	return len(g.Conflicts()) == 0
+}
+
+// HasIntersection
+// 判断两个符号集是否有交集
+func HasIntersection(slice1, slice2 []Symbol) bool {
+	elementMap := make(map[Symbol]bool)
+	for _, elem := range slice1 {
+		elementMap[elem] = true
+	}
+	// 遍历slice2，查找是否存在于elementMap中的元素
+	for _, elem := range slice2 {
+		if _, found := elementMap[elem]; found {
+			return true
+		}
+	}
+	return false
+}
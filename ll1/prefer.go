@@ -0,0 +1,140 @@
+package ll1
+
+import "fmt"
+
+// Preference
+// %prefer 指令：非终结符在向前看符号为Lookahead时优先选择Alternative，用于解决LL(1)冲突，
+// 例如悬挂else：%prefer S_1 e -> e S 。
+// 名字指的是GInit改写之后的文法
+type Preference struct {
+	NonTerminal Symbol
+	Lookahead   Symbol
+	Alternative Alternative
+	// Line 指令在文法文件中的行号，不是从文件中读取时为0
+	Line int
+}
+
+func (p Preference) String() string {
+	return fmt.Sprintf("%%prefer %s %s -> %s", p.NonTerminal.Value, p.Lookahead.Value, SymbolsToString(p.Alternative.Symbols))
+}
+
+// PreferenceError
+// %prefer 指令中的非终结符或备选项在改写后的文法中不存在，
+// 或者指定的表项没有冲突、向前看符号不在所选备选项的select集中
+type PreferenceError struct {
+	Preference Preference
+	Msg        string
+}
+
+func (e *PreferenceError) Error() string {
+	if e.Preference.Line > 0 {
+		return fmt.Sprintf("line %d: %v: %s", e.Preference.Line, e.Preference, e.Msg)
+	}
+	return fmt.Sprintf("%v: %s", e.Preference, e.Msg)
+}
+
+// checkPreferences
+// 检查每条%prefer指令指向改写后文法中存在的备选项，向前看符号在这个备选项的select集中，
+// 并且 M[非终结符, 向前看符号] 是这个备选项参与的冲突，不能用%prefer改写没有冲突的表项。
+// 向前看符号被换成预测分析表的列（结束符的列是EndSymbol，不是同名的终结符）。
+// 需要先计算first集和follow集
+func (g *Grammar) checkPreferences() error {
+	alternatives := g.alternativesByLeft()
+	conflicts := g.Conflicts()
+	for i, pref := range g.Preferences {
+		lookahead := g.lookahead(pref.Lookahead.Value)
+		g.Preferences[i].Lookahead = lookahead
+		alts, ok := alternatives[pref.NonTerminal]
+		if !ok {
+			return &PreferenceError{Preference: pref, Msg: fmt.Sprintf("%s is not a nonterminal", pref.NonTerminal.Value)}
+		}
+		found := false
+		for _, alt := range alts {
+			if sameSymbols(alt.Symbols, pref.Alternative.Symbols) {
+				// 使用文法中的备选项，符号的IsTerminal与文法一致
+				g.Preferences[i].Alternative = alt
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &PreferenceError{Preference: pref, Msg: fmt.Sprintf("%s has no alternative %s",
+				pref.NonTerminal.Value, SymbolsToString(pref.Alternative.Symbols))}
+		}
+		alt := g.Preferences[i].Alternative
+		if !NewSymbolSet(g.Select(pref.NonTerminal, alt.Symbols)...).Has(lookahead) {
+			return &PreferenceError{Preference: pref, Msg: fmt.Sprintf("%s is not in select(%s -> %s)",
+				lookahead.Value, pref.NonTerminal.Value, SymbolsToString(alt.Symbols))}
+		}
+		if !inConflict(conflicts, pref.NonTerminal, lookahead, alt) {
+			return &PreferenceError{Preference: pref, Msg: fmt.Sprintf("M[%s,%s] has no conflict to resolve",
+				pref.NonTerminal.Value, lookahead.Value)}
+		}
+	}
+	return nil
+}
+
+// inConflict
+// alt是否参与了 M[nt, lookahead] 上的冲突
+func inConflict(conflicts []Conflict, nt, lookahead Symbol, alt Alternative) bool {
+	for _, c := range conflicts {
+		if c.NonTerminal != nt || !NewSymbolSet(c.Lookahead...).Has(lookahead) {
+			continue
+		}
+		if sameSymbols(alt.Symbols, c.First.Symbols) || sameSymbols(alt.Symbols, c.Second.Symbols) {
+			return true
+		}
+	}
+	return false
+}
+
+// preferred
+// 返回%prefer为 M[nt, lookahead] 指定的备选项
+func (g Grammar) preferred(nt, lookahead Symbol) (Alternative, bool) {
+	for _, pref := range g.Preferences {
+		if pref.NonTerminal.Value == nt.Value && pref.Lookahead.Value == lookahead.Value {
+			return pref.Alternative, true
+		}
+	}
+	return Alternative{}, false
+}
+
+// resolves
+// 冲突中的每个向前看符号是否都有%prefer选择了冲突的两个备选项之一
+func (g Grammar) resolves(c Conflict) bool {
+	for _, s := range c.Lookahead {
+		alt, ok := g.preferred(c.NonTerminal, s)
+		if !ok || !sameSymbols(alt.Symbols, c.First.Symbols) && !sameSymbols(alt.Symbols, c.Second.Symbols) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyPreferences
+// 用%prefer选择的备选项覆盖已解决的冲突所在的表项，其他表项保持不变
+func (g *Grammar) applyPreferences() {
+	for _, c := range g.Conflicts() {
+		if !c.Resolved {
+			continue
+		}
+		for _, lookahead := range c.Lookahead {
+			alt, _ := g.preferred(c.NonTerminal, lookahead)
+			g.Predict[c.NonTerminal][lookahead] = Production{Left: c.NonTerminal, Right: []Alternative{alt}}
+		}
+	}
+}
+
+// sameSymbols
+// 比较两个符号串的值，不比较IsTerminal
+func sameSymbols(a, b []Symbol) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package ll1
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreferEndMarker(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		end  string
+	}{
+		{"default end marker", "%prefer S '#' -> A\nS -> A | B ;\nA -> a | ;\nB -> b | ;", "#"},
+		{"custom end marker", "%end '$'\n%prefer S '$' -> A\nS -> A | B ;\nA -> a | ;\nB -> b | ;", "$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, err := g.GInit()
+			if err != nil || !ok {
+				t.Fatalf("GInit() = %v, %v, want true, nil", ok, err)
+			}
+			end := g.EndSymbol()
+			if end.Value != tt.end {
+				t.Fatalf("EndSymbol() = %q, want %q", end.Value, tt.end)
+			}
+			prod, ok := g.Predict[Symbol{Value: "S"}][end]
+			if !ok {
+				t.Fatalf("M[S,%s] is empty", tt.end)
+			}
+			if got := prod.String(); got != "S -> A" {
+				t.Errorf("M[S,%s] = %s, want S -> A", tt.end, got)
+			}
+		})
+	}
+}
+
+func TestPreferWithoutConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{"lookahead not in select", "%prefer S b -> a\nS -> a | b ;", "b is not in select(S -> a)"},
+		{"no conflict", "%prefer S a -> a\nS -> a | b ;", "M[S,a] has no conflict to resolve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = g.GInit()
+			var prefErr *PreferenceError
+			if !errors.As(err, &prefErr) {
+				t.Fatalf("GInit() = %v, want PreferenceError", err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("error %q does not mention %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+func TestPreferOnlyConflictingCells(t *testing.T) {
+	g, err := ParseGrammar("%prefer S_1 e -> e S\nS -> i S | i S e S | a ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := g.GInit()
+	if err != nil || !ok {
+		t.Fatalf("GInit() = %v, %v, want true, nil", ok, err)
+	}
+	row := g.Predict[Symbol{Value: "S_1"}]
+	want := map[string]string{"e": "S_1 -> eS", "#": "S_1 -> ε"}
+	for lookahead, prod := range want {
+		col := g.lookahead(lookahead)
+		if got := row[col].String(); got != prod {
+			t.Errorf("M[S_1,%s] = %s, want %s", lookahead, got, prod)
+		}
+	}
+	tokens, err := g.Tokenize("i i a e a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := g.Parse(tokens); !result.Accepted {
+		t.Errorf("Parse rejected i i a e a: %v", result.Errors)
+	}
+}
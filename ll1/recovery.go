@@ -0,0 +1,77 @@
+package ll1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError
+// 分析过程中发现的语法错误：Token是出错时输入中的当前单词，Expected是此时可以接受的终结符，
+// End为true表示出错时已经读到输入末尾的结束符
+type ParseError struct {
+	Token    Token
+	Expected []Symbol
+	End      bool
+}
+
+func (e ParseError) Error() string {
+	expected := make([]string, len(e.Expected))
+	for i, s := range e.Expected {
+		expected[i] = s.Value
+	}
+	found := fmt.Sprintf("unexpected %q", e.Token.Text)
+	if e.End {
+		found = "unexpected end of input"
+	}
+	return fmt.Sprintf("%s: %s, expected %s", e.Token.Pos, found, strings.Join(expected, ", "))
+}
+
+// SyncSet
+// 非终结符的同步集合：FOLLOW(A)∪{结束符}。恢复时若输入的当前单词在同步集合中，就弹出A，
+// 认为A已经分析完，从A后面的符号继续分析
+func (g Grammar) SyncSet(nt Symbol) []Symbol {
+	result := []Symbol{g.EndSymbol()}
+	for _, s := range g.FollowSet[nt].Symbols() {
+		if !s.IsEnd() {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// recover
+// 恐慌模式的错误恢复，返回这一步采取的动作：
+// 1. 栈顶是终结符但与输入不匹配，弹出栈顶终结符，相当于补上缺少的单词
+// 2. 栈顶是非终结符A，输入的当前单词在A的同步集合中，弹出A
+// 3. 否则跳过输入的当前单词；输入已经到结束符时不能再跳过，只能弹出A
+// 栈顶是结束符时说明输入有多余的单词，全部跳过
+func (g Grammar) recover(top Symbol, lookahead string) ActionKind {
+	if top.IsEnd() {
+		return ActionSkip
+	}
+	if top.IsTerminal || lookahead == g.EndSymbol().Value {
+		return ActionPop
+	}
+	for _, s := range g.SyncSet(top) {
+		if s.Value == lookahead {
+			return ActionPop
+		}
+	}
+	return ActionSkip
+}
+
+// expected
+// 出错时可以接受的终结符：栈顶是终结符或结束符时就是它本身，是非终结符时是预测分析表中这一行有产生式的终结符
+func (g Grammar) expected(top Symbol) []Symbol {
+	if top.IsTerminal || top.IsEnd() {
+		return []Symbol{top}
+	}
+	var result []Symbol
+	for s := range g.Predict[top] {
+		if !s.IsEpsilon() {
+			result = append(result, s)
+		}
+	}
+	sortSymbols(result, g.symbolRank())
+	return result
+}
@@ -0,0 +1,129 @@
+package ll1
+
+import (
+	"strings"
+	"testing"
+)
+
+// actions
+// 分析过程中每一步的动作，发现新错误的一步后面加上!
+func actions(steps []Step) string {
+	var result []string
+	for _, s := range steps {
+		action := s.Action.String()
+		if s.Error != nil {
+			action += "!"
+		}
+		result = append(result, action)
+	}
+	return strings.Join(result, " ")
+}
+
+func TestParseRecovery(t *testing.T) {
+	g, err := LoadGrammarFile("../examples/expr.ll1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := g.GInit(); err != nil || !ok {
+		t.Fatalf("GInit() = %v, %v", ok, err)
+	}
+	type wantError struct {
+		pos      string
+		text     string
+		end      bool
+		expected string
+	}
+	tests := []struct {
+		input   string
+		errors  []wantError
+		actions string
+	}{
+		{
+			// 第二个+时term不能展开，+在term的同步集合中，弹出term；)时只剩结束符，跳过)和后面的id
+			input: "id + + id ) id",
+			errors: []wantError{
+				{"1:6", "+", false, "(, id, num"},
+				{"1:11", ")", false, "#"},
+			},
+			actions: "expand expand expand match expand expand match pop! expand match expand expand match expand expand skip! skip error",
+		},
+		{
+			// 缺少)：栈顶终结符)与结束符不匹配，弹出)
+			input:   "( id",
+			errors:  []wantError{{"1:5", "", true, ")"}},
+			actions: "expand expand expand match expand expand expand match expand expand pop! expand expand error",
+		},
+		{
+			// *不在term的同步集合中，跳过*，再用id展开term
+			input:   "id + * id",
+			errors:  []wantError{{"1:6", "*", false, "(, id, num"}},
+			actions: "expand expand expand match expand expand match skip! expand expand match expand expand error",
+		},
+		{
+			input:   "id * ( num )",
+			actions: "expand expand expand match expand match expand match expand expand expand match expand expand match expand expand accept",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens, err := g.Tokenize(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := g.Parse(tokens)
+			if result.Accepted != (len(tt.errors) == 0) {
+				t.Errorf("Accepted = %v", result.Accepted)
+			}
+			if !result.Accepted && result.Tree != nil {
+				t.Errorf("rejected input has a parse tree")
+			}
+			if len(result.Errors) != len(tt.errors) {
+				t.Fatalf("errors = %v, want %d", result.Errors, len(tt.errors))
+			}
+			for i, want := range tt.errors {
+				e := result.Errors[i]
+				expected := make([]string, len(e.Expected))
+				for j, s := range e.Expected {
+					expected[j] = s.Value
+				}
+				if e.Token.Pos.String() != want.pos || e.Token.Text != want.text || e.End != want.end || strings.Join(expected, ", ") != want.expected {
+					t.Errorf("error %d = %s %q end=%v expected {%s}, want %s %q end=%v expected {%s}",
+						i, e.Token.Pos, e.Token.Text, e.End, strings.Join(expected, ", "), want.pos, want.text, want.end, want.expected)
+				}
+			}
+			if got := actions(result.Steps); got != tt.actions {
+				t.Errorf("actions:\n got %s\nwant %s", got, tt.actions)
+			}
+		})
+	}
+}
+
+func TestSyncSet(t *testing.T) {
+	g, err := ParseGrammar("%end '$'\nS -> A b | c ; A -> a | ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	sync := g.SyncSet(Symbol{Value: "A"})
+	if got := SymbolsToString(sync); got != "$b" || !sync[0].IsEnd() {
+		t.Errorf("SyncSet(A) = %s, want the end marker $ and b", got)
+	}
+	tests := []struct {
+		top       Symbol
+		lookahead string
+		want      ActionKind
+	}{
+		{Symbol{Value: "A"}, "b", ActionPop},
+		{Symbol{Value: "A"}, "c", ActionSkip},
+		{Symbol{Value: "A"}, "$", ActionPop},
+		{Symbol{Value: "b", IsTerminal: true}, "c", ActionPop},
+		{g.EndSymbol(), "c", ActionSkip},
+	}
+	for _, tt := range tests {
+		if got := g.recover(tt.top, tt.lookahead); got != tt.want {
+			t.Errorf("recover(%s, %s) = %v, want %v", tt.top.Value, tt.lookahead, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ll1
+
+// Rewrite
+// 一次文法改写的结果：Productions是改写后的全部产生式，Added是新引入的非终结符，
+// Removed是删除的非终结符，Changed为false时文法没有变化
+type Rewrite struct {
+	Name        string
+	Changed     bool
+	Productions []Production
+	Added       []Symbol
+	Removed     []Symbol
+}
+
+// copyProductions
+// 深拷贝产生式，改写结果中的产生式不会随着文法的后续改写而变化
+func copyProductions(prods []Production) []Production {
+	result := make([]Production, len(prods))
+	for i, prod := range prods {
+		result[i] = Production{Left: prod.Left, Right: make([]Alternative, len(prod.Right))}
+		for j, alt := range prod.Right {
+			result[i].Right[j] = Alternative{Symbols: append([]Symbol(nil), alt.Symbols...)}
+		}
+	}
+	return result
+}
+
+// freshNonTerminal
+// 返回一个文法中没有用过、也不在reserved中的非终结符，名字为base，已被占用时依次在后面加上suffix
+func (g *Grammar) freshNonTerminal(base, suffix string, reserved ...Symbol) Symbol {
+	used := make(map[string]bool)
+	for _, s := range reserved {
+		used[s.Value] = true
+	}
+	for _, prod := range g.Productions {
+		used[prod.Left.Value] = true
+		for _, alt := range prod.Right {
+			for _, sym := range alt.Symbols {
+				used[sym.Value] = true
+			}
+		}
+	}
+	name := base
+	for used[name] {
+		name += suffix
+	}
+	return Symbol{Value: name, IsTerminal: false}
+}
+
+// nonTerminalOrder
+// 按产生式左部第一次出现的顺序返回非终结符
+func (g *Grammar) nonTerminalOrder() []Symbol {
+	seen := make(map[Symbol]bool)
+	var order []Symbol
+	for _, prod := range g.Productions {
+		if !seen[prod.Left] {
+			seen[prod.Left] = true
+			order = append(order, prod.Left)
+		}
+	}
+	return order
+}
+
+// alternativesByLeft
+// 把左部相同的产生式的备选项合并在一起
+func (g *Grammar) alternativesByLeft() map[Symbol][]Alternative {
+	result := make(map[Symbol][]Alternative)
+	for _, prod := range g.Productions {
+		result[prod.Left] = append(result[prod.Left], prod.Right...)
+	}
+	return result
+}
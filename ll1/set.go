@@ -0,0 +1,104 @@
+package ll1
+
+import (
+	"sort"
+	"strings"
+)
+
+// SymbolSet
+// 保持顺序的符号集合，Symbols按加入的顺序返回，由InitializeFirstSet等计算出的集合按文法中的顺序排列
+type SymbolSet struct {
+	symbols []Symbol
+	index   map[Symbol]bool
+}
+
+// NewSymbolSet
+// 创建包含symbols的集合
+func NewSymbolSet(symbols ...Symbol) *SymbolSet {
+	s := &SymbolSet{index: make(map[Symbol]bool)}
+	for _, sym := range symbols {
+		s.Add(sym)
+	}
+	return s
+}
+
+// Add
+// 加入符号，返回符号原来是否不在集合中
+func (s *SymbolSet) Add(sym Symbol) bool {
+	if s.index[sym] {
+		return false
+	}
+	s.index[sym] = true
+	s.symbols = append(s.symbols, sym)
+	return true
+}
+
+// Has
+// 判断符号是否在集合中，nil集合视为空集
+func (s *SymbolSet) Has(sym Symbol) bool {
+	return s != nil && s.index[sym]
+}
+
+// Len
+// 集合中符号的个数
+func (s *SymbolSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.symbols)
+}
+
+// Symbols
+// 按顺序返回集合中的符号
+func (s *SymbolSet) Symbols() []Symbol {
+	if s == nil {
+		return nil
+	}
+	return append([]Symbol(nil), s.symbols...)
+}
+
+// String
+// 以 {a, b, c} 的形式返回集合
+func (s *SymbolSet) String() string {
+	values := make([]string, 0, s.Len())
+	for _, sym := range s.Symbols() {
+		values = append(values, sym.Value)
+	}
+	return "{" + strings.Join(values, ", ") + "}"
+}
+
+// sortBy
+// 按rank从小到大重新排列集合中的符号
+func (s *SymbolSet) sortBy(rank func(Symbol) int) {
+	sortSymbols(s.symbols, rank)
+}
+
+func sortSymbols(symbols []Symbol, rank func(Symbol) int) {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return rank(symbols[i]) < rank(symbols[j])
+	})
+}
+
+// symbolRank
+// 文法中符号的顺序：非终结符按声明的顺序，终结符按在产生式中第一次出现的顺序，
+// 然后是结束符和ε，不在文法中的符号排在最后
+func (g *Grammar) symbolRank() func(Symbol) int {
+	rank := make(map[string]int)
+	for _, nt := range g.GetNonTerminals() {
+		rank[nt.Value] = len(rank)
+	}
+	for _, t := range g.GetTerminals() {
+		rank[t.Value] = len(rank)
+	}
+	for _, v := range []string{g.EndSymbol().Value, Epsilon.Value} {
+		if _, ok := rank[v]; !ok {
+			rank[v] = len(rank)
+		}
+	}
+	return func(s Symbol) int {
+		if r, ok := rank[s.Value]; ok {
+			return r
+		}
+		return len(rank)
+	}
+}
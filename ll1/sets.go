@@ -0,0 +1,270 @@
+package ll1
+
+// AllNullable
+// 得到字符串的可空性，ε和空串都是可空的
+func (g *Grammar) AllNullable(symbols []Symbol) bool {
+	for _, symbol := range symbols {
+		if symbol.IsEpsilon() {
+			continue
+		}
+		if symbol.IsTerminal || !g.Nullable[symbol.Value] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetFirst
+// 得到字符串的first集，按文法中的顺序排列
+// 依次并入每个符号的first集（除ε之外），遇到终结符或不可空的非终结符时停止，所有符号都可空时加入ε
+func (g Grammar) GetFirst(symbols []Symbol) []Symbol {
+	result := NewSymbolSet()
+	for _, symbol := range symbols {
+		if symbol.IsEpsilon() {
+			continue
+		}
+		if symbol.IsTerminal {
+			result.Add(symbol)
+			break
+		}
+		for _, s := range g.FirstSet[symbol].Symbols() {
+			if !s.IsEpsilon() {
+				result.Add(s)
+			}
+		}
+		if !g.Nullable[symbol.Value] {
+			break
+		}
+	}
+	if g.AllNullable(symbols) {
+		result.Add(Epsilon)
+	}
+	result.sortBy(g.symbolRank())
+	return result.Symbols()
+}
+
+// Select
+// select集是对每个产生式进行处理，结果按文法中的顺序排列
+// 1.select(S->ab)=first(a)
+// select(S->AB)，若AB能得出->ε，则select(S->AB)={first(AB)-{ε}}∪follow(S)。反之，select(S->AB)=first(AB)
+// right为空或只有ε时按空串处理，select集为follow(S)；select集中只有终结符和结束符，没有ε
+func (g Grammar) Select(left Symbol, right []Symbol) []Symbol {
+	result := NewSymbolSet()
+	if len(right) > 0 && right[0].IsTerminal {
+		result.Add(right[0])
+		return result.Symbols()
+	}
+	for _, s := range g.GetFirst(right) {
+		if !s.IsEpsilon() {
+			result.Add(s)
+		}
+	}
+	if g.AllNullable(right) {
+		for _, s := range g.FollowSet[left].Symbols() {
+			result.Add(s)
+		}
+	}
+	result.sortBy(g.symbolRank())
+	return result.Symbols()
+}
+
+// InitializeNullable
+// 以S->ε为基础循环遍历产生式，找到所有能推导出空串的非终结符存入空串表
+func (g *Grammar) InitializeNullable() {
+	g.Nullable = make(map[string]bool)
+	// 初始化，将所有非终结符设置为不可空
+	for _, production := range g.Productions {
+		// 设置非终结符的可空性为 false
+		g.Nullable[production.Left.Value] = false
+	}
+
+	// 反复遍历产生式，直到没有新的可空非终结符被添加
+	// 使用一个布尔值变量 `changed` 来跟踪是否有新的可空非终结符被找到
+	changed := true
+	for changed {
+		// 设置 `changed` 为 false，表示在本轮遍历中尚未找到新的可空非终结符
+		changed = false
+
+		// 遍历产生式
+		for _, production := range g.Productions {
+			// 如果当前非终结符尚未被标记为可空
+			if !g.Nullable[production.Left.Value] {
+				// 遍历产生式右侧的替代项
+				for _, alternative := range production.Right {
+					// 假定当前替代项可导出空串
+					nullable := true
+
+					// 遍历替代项中的符号
+					for _, symbol := range alternative.Symbols {
+						// 如果符号是终结符，或者符号是一个不可空的非终结符
+						if symbol.IsEpsilon() {
+							//空串，跳过
+							continue
+						}
+						if symbol.IsTerminal || !g.Nullable[symbol.Value] {
+							// 将 `nullable` 设置为 false，表示当前替代项不可导出空串
+							nullable = false
+							break
+						}
+					}
+					// 如果当前替代项可导出空串
+					if nullable {
+						// 将左侧非终结符标记为可空
+						g.Nullable[production.Left.Value] = true
+						// 将 `changed` 设置为 true，表示在本轮遍历中找到了新的可空非终结符
+						changed = true
+						// 跳出替代项遍历循环
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// InitializeFirstSet
+// 终结符的first集为自己
+// 非终结符的first集并入（除ε之外）
+// 如果可空继续判断下一个字符，如果都可空则加入ε
+func (g *Grammar) InitializeFirstSet() {
+	g.FirstSet = make(map[Symbol]*SymbolSet)
+
+	// 遍历产生式，初始化每个符号的 First 集
+	for _, production := range g.Productions {
+		if _, ok := g.FirstSet[production.Left]; !ok {
+			g.FirstSet[production.Left] = NewSymbolSet()
+		}
+		for _, alternative := range production.Right {
+			for _, symbol := range alternative.Symbols {
+				if symbol.IsEpsilon() {
+					continue
+				}
+				if _, ok := g.FirstSet[symbol]; !ok {
+					g.FirstSet[symbol] = NewSymbolSet()
+				}
+			}
+		}
+	}
+
+	// 将终结符添加到它们自己的 First 集中
+	for symbol, symbolFirstSet := range g.FirstSet {
+		if symbol.IsTerminal {
+			symbolFirstSet.Add(symbol)
+		}
+	}
+
+	// 计算非终结符的 First 集
+	changed := true // 反复遍历产生式，直到没有新的符号被添加到 First 集
+	for changed {
+		changed = false
+		for _, production := range g.Productions {
+			left := production.Left
+			for _, alternative := range production.Right {
+				nullable := true
+				for _, symbol := range alternative.Symbols {
+					if symbol.IsEpsilon() {
+						continue
+					}
+					// 如果符号是非终结符
+					if !symbol.IsTerminal {
+						// 将 symbol 的 First 集合并到 left 的 First 集中
+						for _, s := range g.FirstSet[symbol].Symbols() {
+							if !s.IsEpsilon() && g.FirstSet[left].Add(s) {
+								changed = true
+							}
+						}
+						// 如果当前符号是不可空的，跳出循环
+						if !g.Nullable[symbol.Value] {
+							nullable = false
+							break
+						}
+					} else {
+						// 如果符号是终结符，将其添加到 left 的 First 集中，并跳出循环
+						if g.FirstSet[left].Add(symbol) {
+							changed = true
+						}
+						nullable = false
+						break
+					}
+				}
+				// 如果所有符号都是可空的，则将 ε 添加到 left 的 First 集中
+				if nullable {
+					if g.FirstSet[left].Add(Epsilon) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	rank := g.symbolRank()
+	for _, set := range g.FirstSet {
+		set.sortBy(rank)
+	}
+}
+
+// InitializeFollowSet
+// 开始符号的follow应该有输入结束符（默认为#，见EndSymbol）
+// 终结符没有follow集
+// 对于非终结符 A，如果 A 后面紧跟着一个终结符 a，则将 a 添加到 A 的 Follow 集中。
+// 对于非终结符 A，如果 A 后面紧跟着一个非终结符 B，则将 B 的 First 集（不包括 "ε"）中的所有符号添加到 A 的 Follow 集中。
+// 对于非终结符 A，如果 A 后面紧跟着一个非终结符 B，且 B 可导出空串（"ε"），则将产生式左侧非终结符的 Follow 集中的所有符号添加到 A 的 Follow 集中。
+func (g *Grammar) InitializeFollowSet() {
+	g.FollowSet = make(map[Symbol]*SymbolSet)
+	// 初始化非终结符的 Follow 集
+	for _, production := range g.Productions {
+		left := production.Left
+		if _, ok := g.FollowSet[left]; !ok {
+			g.FollowSet[left] = NewSymbolSet()
+		}
+	}
+	// 将文法开始符号的 Follow 集设为 { 结束符 }
+	g.FollowSet[g.Start] = NewSymbolSet(g.EndSymbol())
+	// 反复遍历产生式，直到 Follow 集不再发生变化
+	changed := true
+	for changed {
+		changed = false
+		for _, production := range g.Productions {
+			left := production.Left
+			//遍历产生式，得到symbol为非终结符A
+			for _, alternative := range production.Right {
+				for i, symbol := range alternative.Symbols {
+					if symbol.IsNonTerminal() {
+						for j := i + 1; j < len(alternative.Symbols); j++ {
+							nextSymbol := alternative.Symbols[j]
+							if nextSymbol.IsTerminal {
+								//终结符，加入
+								if g.FollowSet[symbol].Add(nextSymbol) {
+									changed = true
+								}
+								break
+							} else {
+								//将 nextSymbol 的 First 集合加入 symbol 的 Follow 集合
+								for _, firstSymbol := range g.FirstSet[nextSymbol].Symbols() {
+									if !firstSymbol.IsEpsilon() && g.FollowSet[symbol].Add(firstSymbol) {
+										changed = true
+									}
+								}
+								// 如果 nextSymbol 可以为空，则继续往后处理
+								if !g.Nullable[nextSymbol.Value] {
+									break
+								}
+							}
+						}
+						// 如果非终结符 A 后面的所有非终结符都可以为空，则将产生式左侧非终结符的 Follow 集中的所有符号添加到 A 的 Follow 集中
+						if g.AllNullable(alternative.Symbols[i+1:]) {
+							for _, followSymbol := range g.FollowSet[left].Symbols() {
+								if g.FollowSet[symbol].Add(followSymbol) {
+									changed = true
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	rank := g.symbolRank()
+	for _, set := range g.FollowSet {
+		set.sortBy(rank)
+	}
+}
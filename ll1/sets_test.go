@@ -0,0 +1,162 @@
+package ll1
+
+import (
+	"strings"
+	"testing"
+)
+
+// setsCase
+// 一个文法的first集、follow集和每个备选项的select集，select的键是产生式的字符串形式
+type setsCase struct {
+	name     string
+	src      string
+	nullable []string
+	first    map[string]string
+	follow   map[string]string
+	sel      map[string]string
+}
+
+func TestSets(t *testing.T) {
+	tests := []setsCase{
+		{
+			name:     "epsilon only alternative",
+			src:      "S -> a A ; A -> ε ;",
+			nullable: []string{"A"},
+			first:    map[string]string{"S": "{a}", "A": "{ε}"},
+			follow:   map[string]string{"S": "{#}", "A": "{#}"},
+			sel:      map[string]string{"S -> aA": "{a}", "A -> ε": "{#}"},
+		},
+		{
+			name:     "empty alternative and eps",
+			src:      "S -> A B ; A -> a | ; B -> b | eps ;",
+			nullable: []string{"S", "A", "B"},
+			first:    map[string]string{"S": "{a, b, ε}", "A": "{a, ε}", "B": "{b, ε}"},
+			follow:   map[string]string{"S": "{#}", "A": "{b, #}", "B": "{#}"},
+			sel: map[string]string{
+				"S -> AB": "{a, b, #}",
+				"A -> a":  "{a}", "A -> ε": "{b, #}",
+				"B -> b": "{b}", "B -> ε": "{#}",
+			},
+		},
+		{
+			name:     "nullable chain",
+			src:      "S -> A d ; A -> B | a ; B -> C | b ; C -> c | ε ;",
+			nullable: []string{"A", "B", "C"},
+			first:    map[string]string{"S": "{d, a, b, c}", "A": "{a, b, c, ε}", "B": "{b, c, ε}", "C": "{c, ε}"},
+			follow:   map[string]string{"S": "{#}", "A": "{d}", "B": "{d}", "C": "{d}"},
+			sel: map[string]string{
+				"S -> Ad": "{d, a, b, c}",
+				"A -> B":  "{d, b, c}", "A -> a": "{a}",
+				"B -> C": "{d, c}", "B -> b": "{b}",
+				"C -> c": "{c}", "C -> ε": "{d}",
+			},
+		},
+		{
+			name:     "custom end marker",
+			src:      "%end '$'\nS -> A '#' | ; A -> a | ;",
+			nullable: []string{"S", "A"},
+			first:    map[string]string{"S": "{#, a, ε}", "A": "{a, ε}"},
+			follow:   map[string]string{"S": "{$}", "A": "{#}"},
+			sel:      map[string]string{"S -> A#": "{#, a}", "S -> ε": "{$}", "A -> a": "{a}", "A -> ε": "{#}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.GInit(); err != nil {
+				t.Fatal(err)
+			}
+			nullable := make(map[string]bool)
+			for _, nt := range tt.nullable {
+				nullable[nt] = true
+			}
+			for _, nt := range g.GetNonTerminals() {
+				if g.Nullable[nt.Value] != nullable[nt.Value] {
+					t.Errorf("Nullable[%s] = %v", nt.Value, g.Nullable[nt.Value])
+				}
+				if got := g.FirstSet[nt].String(); got != tt.first[nt.Value] {
+					t.Errorf("FIRST(%s) = %s, want %s", nt.Value, got, tt.first[nt.Value])
+				}
+				if got := g.FollowSet[nt].String(); got != tt.follow[nt.Value] {
+					t.Errorf("FOLLOW(%s) = %s, want %s", nt.Value, got, tt.follow[nt.Value])
+				}
+			}
+			seen := 0
+			for _, prod := range g.Productions {
+				for _, alt := range prod.Right {
+					key := prod.Left.Value + " -> " + SymbolsToString(alt.Symbols)
+					want, ok := tt.sel[key]
+					if !ok {
+						t.Errorf("unexpected alternative %s", key)
+						continue
+					}
+					seen++
+					if got := NewSymbolSet(g.Select(prod.Left, alt.Symbols)...).String(); got != want {
+						t.Errorf("SELECT(%s) = %s, want %s", key, got, want)
+					}
+				}
+			}
+			if seen != len(tt.sel) {
+				t.Errorf("checked %d alternatives, want %d", seen, len(tt.sel))
+			}
+		})
+	}
+}
+
+// TestSymbolKinds
+// ε和结束符是单独的两类符号，与同名的终结符不相等
+func TestSymbolKinds(t *testing.T) {
+	g, err := ParseGrammar("%end '$'\nS -> A '#' | ; A -> a | eps ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	end := g.EndSymbol()
+	tests := []struct {
+		name                        string
+		sym                         Symbol
+		epsilon, isEnd, nonTerminal bool
+	}{
+		{"epsilon", Epsilon, true, false, false},
+		{"end marker", end, false, true, false},
+		{"terminal", Symbol{Value: "#", IsTerminal: true}, false, false, false},
+		{"nonterminal", Symbol{Value: "A"}, false, false, true},
+	}
+	for _, tt := range tests {
+		if tt.sym.IsEpsilon() != tt.epsilon || tt.sym.IsEnd() != tt.isEnd || tt.sym.IsNonTerminal() != tt.nonTerminal {
+			t.Errorf("%s: IsEpsilon=%v IsEnd=%v IsNonTerminal=%v", tt.name, tt.sym.IsEpsilon(), tt.sym.IsEnd(), tt.sym.IsNonTerminal())
+		}
+	}
+
+	// FIRST(A)里的ε是EpsilonMarker，FOLLOW(S)里的$是EndMarker，FOLLOW(A)里的#是终结符
+	for _, s := range g.FirstSet[Symbol{Value: "A"}].Symbols() {
+		if s.Value == Epsilon.Value && s != Epsilon {
+			t.Errorf("FIRST(A) has ε of kind %v", s.Kind)
+		}
+	}
+	if follow := g.FollowSet[Symbol{Value: "S"}]; !follow.Has(end) || follow.Len() != 1 {
+		t.Errorf("FOLLOW(S) = %s, want {$} of kind EndMarker", follow)
+	}
+	if follow := g.FollowSet[Symbol{Value: "A"}]; !follow.Has(Symbol{Value: "#", IsTerminal: true}) || follow.Has(Symbol{Value: "#", Kind: EndMarker}) {
+		t.Errorf("FOLLOW(A) = %s, want the terminal #", follow)
+	}
+	for _, s := range g.GetTerminals() {
+		if s.IsEnd() || s.IsEpsilon() {
+			t.Errorf("GetTerminals() contains %q of kind %v", s.Value, s.Kind)
+		}
+	}
+	var columns []string
+	for s := range g.Predict[Symbol{Value: "S"}] {
+		if s.IsEnd() {
+			columns = append(columns, s.Value)
+		}
+	}
+	if strings.Join(columns, ",") != "$" {
+		t.Errorf("end marker columns of M[S] = %v, want [$]", columns)
+	}
+}
@@ -0,0 +1,147 @@
+package ll1
+
+import "fmt"
+
+// ExtractCommonFactors
+// 提取公因子：将产生式中的公共前缀提取出来，简化文法。
+// A->αβ1|αβ2|…|αβn|γ 提取后为 A->αA_1|γ，A_1->β1|β2|…|βn，
+// 新产生式放入工作表继续提取，直到所有非终结符的备选项都没有公共前缀为止。
+// 新的非终结符以最初的非终结符命名为 A_1、A_2…，不与文法中已有的符号重名；
+// 备选项保持原来的顺序，提取出的备选项放在这一组第一个备选项的位置
+func (g *Grammar) ExtractCommonFactors() Rewrite {
+	rewrite := Rewrite{Name: "extractCommonFactors"}
+	order := g.nonTerminalOrder()
+	alts := g.alternativesByLeft()
+	// origin 记录新非终结符来自哪个最初的非终结符，counter 记录每个最初的非终结符用到了第几个编号
+	origin := make(map[Symbol]Symbol)
+	counter := make(map[Symbol]int)
+	worklist := append([]Symbol(nil), order...)
+	for len(worklist) > 0 {
+		nt := worklist[0]
+		worklist = worklist[1:]
+		root, ok := origin[nt]
+		if !ok {
+			root = nt
+		}
+		// 按第一个符号分组，组的顺序是第一个符号第一次出现的顺序
+		var firstSymbols []Symbol
+		groups := make(map[Symbol][]Alternative)
+		for _, alternative := range dedupAlternatives(alts[nt]) {
+			firstSymbol := alternative.Symbols[0]
+			if _, ok := groups[firstSymbol]; !ok {
+				firstSymbols = append(firstSymbols, firstSymbol)
+			}
+			groups[firstSymbol] = append(groups[firstSymbol], alternative)
+		}
+		newAlternatives := []Alternative{}
+		for _, firstSymbol := range firstSymbols {
+			alternatives := groups[firstSymbol]
+			if len(alternatives) == 1 {
+				newAlternatives = append(newAlternatives, alternatives[0])
+				continue
+			}
+			// 查找多个备选项的最长公共前缀，生成新的非终结符
+			commonPrefix := findLongestCommonPrefix(alternatives)
+			var newNonTerminal Symbol
+			for {
+				counter[root]++
+				newNonTerminal = Symbol{Value: fmt.Sprintf("%s_%d", root.Value, counter[root]), IsTerminal: false}
+				if g.freshNonTerminal(newNonTerminal.Value, "_", rewrite.Added...) == newNonTerminal {
+					break
+				}
+			}
+			origin[newNonTerminal] = root
+			alts[newNonTerminal] = removeCommonPrefix(alternatives, commonPrefix)
+			rewrite.Added = append(rewrite.Added, newNonTerminal)
+			worklist = append(worklist, newNonTerminal)
+			// 创建一个新的备选项，包括公共前缀和新的非终结符
+			newAlternatives = append(newAlternatives, Alternative{Symbols: concatSymbols(commonPrefix, []Symbol{newNonTerminal})})
+		}
+		if len(newAlternatives) != len(alts[nt]) {
+			rewrite.Changed = true
+		}
+		alts[nt] = newAlternatives
+	}
+	if rewrite.Changed {
+		prods := make([]Production, 0, len(order)+len(rewrite.Added))
+		for _, nt := range append(order, rewrite.Added...) {
+			prods = append(prods, Production{Left: nt, Right: alts[nt]})
+		}
+		g.Productions = prods
+	}
+	rewrite.Productions = copyProductions(g.Productions)
+	return rewrite
+}
+
+// dedupAlternatives
+// 去掉重复的备选项，保持原来的顺序，空的备选项视为ε
+func dedupAlternatives(alternatives []Alternative) []Alternative {
+	var result []Alternative
+	seen := make(map[string]bool)
+	for _, alt := range alternatives {
+		if len(alt.Symbols) == 0 {
+			alt = Alternative{Symbols: []Symbol{Epsilon}}
+		}
+		key := fmt.Sprint(alt.Symbols)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, alt)
+		}
+	}
+	return result
+}
+func findLongestCommonPrefix(alternatives []Alternative) []Symbol {
+	if len(alternatives) == 0 {
+		return nil
+	}
+	// 获取第一个备选项的符号列表作为参考
+	reference := alternatives[0].Symbols
+	// 初始化最长公共前缀的长度为参考备选项的长度
+	maxLength := len(reference)
+	// 遍历其余的备选项
+	for _, alternative := range alternatives[1:] {
+		i := 0
+		// 比较当前备选项与参考备选项的符号，找出公共前缀的长度存为i
+		for i < len(alternative.Symbols) && i < maxLength {
+			if alternative.Symbols[i] != reference[i] {
+				break
+			}
+			i++
+		}
+
+		// 更新最长公共前缀的长度
+		if i < maxLength {
+			maxLength = i
+		}
+	}
+
+	// 返回最长公共前缀的符号列表
+	return reference[:maxLength]
+}
+func removeCommonPrefix(alternatives []Alternative, prefix []Symbol) []Alternative {
+	// 创建一个新的备选项列表，用于存储移除公共前缀后的备选项
+	newAlternatives := []Alternative{}
+	// 获取公共前缀的长度
+	prefixLength := len(prefix)
+	// 遍历原始备选项列表
+	for _, alternative := range alternatives {
+		// 创建一个新的符号列表，用于存储移除公共前缀后的符号
+		newSymbols := []Symbol{}
+
+		// 如果原始符号列表的长度大于公共前缀的长度，则从原始符号列表中移除公共前缀，否则剩下空串
+		if len(alternative.Symbols) > prefixLength {
+			newSymbols = alternative.Symbols[prefixLength:]
+		} else {
+			newSymbols = append(newSymbols, Epsilon)
+		}
+
+		// 将移除公共前缀后的符号列表添加到新的备选项中
+		newAlternative := Alternative{Symbols: newSymbols}
+
+		// 将新的备选项添加到新的备选项列表中
+		newAlternatives = append(newAlternatives, newAlternative)
+	}
+
+	// 返回移除公共前缀后的新的备选项列表
+	return newAlternatives
+}
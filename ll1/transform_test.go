@@ -0,0 +1,96 @@
+package ll1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractCommonFactors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		want  []string
+		added string
+	}{
+		{
+			// E_1 提取后还有公共前缀b，继续提取为E_2，名字都以最初的E命名
+			name: "nested",
+			src:  "E -> a b c | a b d | a e | f ;",
+			want: []string{
+				"E -> a E_1|f",
+				"E_1 -> b E_2|e",
+				"E_2 -> c|d",
+			},
+			added: "E_1 E_2",
+		},
+		{
+			name: "longest prefix",
+			src:  "E -> i E t S | i E t S e S | a ; S -> x ;",
+			want: []string{
+				"E -> i E t S E_1|a",
+				"S -> x",
+				"E_1 -> ε|eS",
+			},
+			added: "E_1",
+		},
+		{
+			// 用户定义的A_1不能被覆盖
+			name: "name taken",
+			src:  "A -> x y | x z | A_1 ; A_1 -> q ;",
+			want: []string{
+				"A -> x A_2|A_1",
+				"A_1 -> q",
+				"A_2 -> y|z",
+			},
+			added: "A_2",
+		},
+		{
+			// 提取出的备选项放在这一组第一个备选项的位置
+			name: "order",
+			src:  "S -> b | a c | d | a e | b f ;",
+			want: []string{
+				"S -> b S_1|a S_2|d",
+				"S_1 -> ε|f",
+				"S_2 -> c|e",
+			},
+			added: "S_1 S_2",
+		},
+		{
+			name: "prefix is whole alternative",
+			src:  "S -> a | a b ;",
+			want: []string{
+				"S -> a S_1",
+				"S_1 -> ε|b",
+			},
+			added: "S_1",
+		},
+		{
+			name: "no common prefix",
+			src:  "S -> a b | c ;",
+			want: []string{"S -> ab|c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := leftRecGrammar(t, tt.src)
+			rewrite := g.ExtractCommonFactors()
+			var got, added []string
+			for _, p := range g.Productions {
+				got = append(got, p.String())
+			}
+			for _, sym := range rewrite.Added {
+				added = append(added, sym.Value)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if rewrite.Changed != (tt.added != "") || strings.Join(added, " ") != tt.added {
+				t.Errorf("Changed = %v, Added = %v, want %q", rewrite.Changed, added, tt.added)
+			}
+			// 结果已经没有公共前缀，再提取一次不会改变文法
+			if again := g.ExtractCommonFactors(); again.Changed || len(again.Added) != 0 {
+				t.Errorf("second pass changed the grammar: %v", again.Added)
+			}
+		})
+	}
+}
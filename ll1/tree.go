@@ -0,0 +1,104 @@
+package ll1
+
+import "strings"
+
+// Node
+// 分析树的结点：非终结符结点的Children是展开时所用备选项中的符号，
+// 终结符叶子的Token是匹配到的单词，ε展开时得到一个Symbol为ε的叶子
+type Node struct {
+	Symbol   Symbol
+	Token    Token
+	Children []*Node
+}
+
+// IsLeaf
+// 终结符和ε都是叶子
+func (n *Node) IsLeaf() bool {
+	return n.Symbol.IsTerminal || n.Symbol.IsEpsilon()
+}
+
+// String
+// 按缩进的树形返回分析树，每行一个结点
+func (n *Node) String() string {
+	var sb strings.Builder
+	sb.WriteString(n.label())
+	sb.WriteString("\n")
+	n.writeChildren(&sb, "")
+	return sb.String()
+}
+
+func (n *Node) label() string {
+	if n.IsLeaf() && n.Token.Text != "" && n.Token.Text != n.Symbol.Value {
+		return n.Symbol.Value + " \"" + n.Token.Text + "\""
+	}
+	return n.Symbol.Value
+}
+
+func (n *Node) writeChildren(sb *strings.Builder, prefix string) {
+	for i, child := range n.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		sb.WriteString(prefix + branch + child.label() + "\n")
+		child.writeChildren(sb, prefix+indent)
+	}
+}
+
+// Leaves
+// 按从左到右的顺序返回所有匹配到输入的终结符叶子，不包括ε
+func (n *Node) Leaves() []*Node {
+	var leaves []*Node
+	n.Walk(func(node *Node) {
+		if node.IsLeaf() && !node.Symbol.IsEpsilon() {
+			leaves = append(leaves, node)
+		}
+	})
+	return leaves
+}
+
+// Walk
+// 先序遍历分析树
+func (n *Node) Walk(visit func(*Node)) {
+	visit(n)
+	for _, child := range n.Children {
+		child.Walk(visit)
+	}
+}
+
+// LeftmostDerivation
+// 返回分析树对应的最左推导中的每个句型，第一个句型是根结点的符号。
+// 先序遍历中非终结符结点的顺序就是最左推导中展开的顺序
+func (n *Node) LeftmostDerivation() [][]Symbol {
+	form := []*Node{n}
+	derivation := [][]Symbol{nodeSymbols(form)}
+	n.Walk(func(node *Node) {
+		if node.IsLeaf() {
+			return
+		}
+		for i, f := range form {
+			if f != node {
+				continue
+			}
+			var replaced []*Node
+			replaced = append(replaced, form[:i]...)
+			for _, child := range node.Children {
+				if !child.Symbol.IsEpsilon() {
+					replaced = append(replaced, child)
+				}
+			}
+			form = append(replaced, form[i+1:]...)
+			derivation = append(derivation, nodeSymbols(form))
+			break
+		}
+	})
+	return derivation
+}
+
+func nodeSymbols(nodes []*Node) []Symbol {
+	symbols := make([]Symbol, len(nodes))
+	for i, node := range nodes {
+		symbols[i] = node.Symbol
+	}
+	return symbols
+}
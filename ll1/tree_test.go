@@ -0,0 +1,108 @@
+package ll1
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseTree(t *testing.T, g *Grammar, input string) *Node {
+	t.Helper()
+	tokens, err := g.Tokenize(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := g.Parse(tokens)
+	if !result.Accepted || result.Tree == nil {
+		t.Fatalf("Parse(%q) = %v", input, result.Errors)
+	}
+	return result.Tree
+}
+
+func derivationStrings(derivation [][]Symbol) []string {
+	result := make([]string, len(derivation))
+	for i, form := range derivation {
+		values := make([]string, len(form))
+		for j, sym := range form {
+			values[j] = sym.Value
+		}
+		result[i] = strings.Join(values, " ")
+	}
+	return result
+}
+
+func TestParseTree(t *testing.T) {
+	g, err := LoadGrammarFile("../examples/expr.ll1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	tree := parseTree(t, g, "id * num")
+	// ε展开得到ε叶子
+	want := strings.Join([]string{
+		"expr",
+		"├── term",
+		"│   ├── factor",
+		"│   │   └── id",
+		"│   └── term'",
+		"│       ├── *",
+		"│       ├── factor",
+		"│       │   └── num",
+		"│       └── term'",
+		"│           └── ε",
+		"└── expr'",
+		"    └── ε",
+	}, "\n") + "\n"
+	if got := tree.String(); got != want {
+		t.Errorf("tree:\n%s\nwant\n%s", got, want)
+	}
+	var leaves []string
+	for _, leaf := range tree.Leaves() {
+		leaves = append(leaves, leaf.Token.Text+"@"+leaf.Token.Pos.String())
+	}
+	if got := strings.Join(leaves, " "); got != "id@1:1 *@1:4 num@1:6" {
+		t.Errorf("Leaves() = %s", got)
+	}
+
+	wantDerivation := []string{
+		"expr",
+		"term expr'",
+		"factor term' expr'",
+		"id term' expr'",
+		"id * factor term' expr'",
+		"id * num term' expr'",
+		"id * num expr'",
+		"id * num",
+	}
+	if got := derivationStrings(tree.LeftmostDerivation()); strings.Join(got, "\n") != strings.Join(wantDerivation, "\n") {
+		t.Errorf("derivation:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantDerivation, "\n"))
+	}
+}
+
+// TestLeftmostDerivationEpsilon
+// ε展开时非终结符从句型中消失，ε不出现在句型中
+func TestLeftmostDerivationEpsilon(t *testing.T) {
+	g, err := ParseGrammar("S -> a S | ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GInit(); err != nil {
+		t.Fatal(err)
+	}
+	tree := parseTree(t, g, "a a")
+	got := derivationStrings(tree.LeftmostDerivation())
+	want := []string{"S", "a S", "a a S", "a a"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("derivation = %q, want %q", got, want)
+	}
+	last := tree.Children[1].Children[1]
+	if len(last.Children) != 1 || !last.Children[0].Symbol.IsEpsilon() || !last.Children[0].IsLeaf() {
+		t.Errorf("last S has children %v, want a single ε leaf", last.Children)
+	}
+
+	tree = parseTree(t, g, "")
+	if got := derivationStrings(tree.LeftmostDerivation()); strings.Join(got, "|") != "S|" {
+		t.Errorf("derivation of the empty input = %q", got)
+	}
+}
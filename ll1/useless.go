@@ -0,0 +1,280 @@
+package ll1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UselessSymbols
+// 文法中无用的符号：
+// Unproductive 推不出任何终结符串的非终结符；
+// Unreachable 从开始符推导不到的非终结符；
+// Undefined 按非终结符使用、却没有产生式的符号，见Grammar.Declared
+type UselessSymbols struct {
+	Unproductive []Symbol
+	Unreachable  []Symbol
+	Undefined    []Symbol
+}
+
+// Empty
+// 文法中是否没有无用的符号
+func (u UselessSymbols) Empty() bool {
+	return len(u.Unproductive) == 0 && len(u.Unreachable) == 0 && len(u.Undefined) == 0
+}
+
+func (u UselessSymbols) String() string {
+	var lines []string
+	add := func(title string, symbols []Symbol) {
+		if len(symbols) > 0 {
+			lines = append(lines, title+": "+NewSymbolSet(symbols...).String())
+		}
+	}
+	add("unproductive", u.Unproductive)
+	add("unreachable", u.Unreachable)
+	add("undefined", u.Undefined)
+	return strings.Join(lines, "\n")
+}
+
+// EmptyLanguageError
+// 开始符推不出任何终结符串，化简会删掉开始符的所有产生式。
+// Unproductive和Undefined是导致这种情况的符号，开始符本身也在Unproductive中
+type EmptyLanguageError struct {
+	Start        Symbol
+	Unproductive []Symbol
+	Undefined    []Symbol
+}
+
+func (e *EmptyLanguageError) Error() string {
+	msg := fmt.Sprintf("start symbol %s derives no terminal string, the language is empty", e.Start.Value)
+	useless := UselessSymbols{Unproductive: e.Unproductive, Undefined: e.Undefined}
+	if !useless.Empty() {
+		msg += " (" + strings.ReplaceAll(useless.String(), "\n", "; ") + ")"
+	}
+	return msg
+}
+
+// emptyLanguage
+// 开始符推不出终结符串时的错误
+func (g *Grammar) emptyLanguage(undefined []Symbol, productive map[string]bool) *EmptyLanguageError {
+	e := &EmptyLanguageError{Start: g.Start, Undefined: undefined}
+	for _, nt := range g.nonTerminalOrder() {
+		if !productive[nt.Value] {
+			e.Unproductive = append(e.Unproductive, nt)
+		}
+	}
+	return e
+}
+
+// undefinedSymbols
+// 没有产生式、但按Declared应当是非终结符的符号，按第一次出现的顺序排列
+func (g *Grammar) undefinedSymbols() []Symbol {
+	strict := false
+	for _, terminal := range g.Declared {
+		if terminal {
+			strict = true
+			break
+		}
+	}
+	lefts := make(map[string]bool)
+	for _, prod := range g.Productions {
+		lefts[prod.Left.Value] = true
+	}
+	result := NewSymbolSet()
+	for _, prod := range g.Productions {
+		for _, alt := range prod.Right {
+			for _, sym := range alt.Symbols {
+				if lefts[sym.Value] || sym.IsEpsilon() {
+					continue
+				}
+				terminal, declared := g.Declared[sym.Value]
+				if declared && !terminal || !declared && strict && !sym.IsTerminal {
+					result.Add(Symbol{Value: sym.Value, IsTerminal: false})
+				}
+			}
+		}
+	}
+	return result.Symbols()
+}
+
+// checkDefined
+// 检查文法中没有未定义的符号，开始符能推出终结符串。
+// 应在MarkTerminals之前调用，之后未定义的符号会被当作终结符
+func (g *Grammar) checkDefined() error {
+	var errs []error
+	undefined := make(map[string]bool)
+	for _, sym := range g.undefinedSymbols() {
+		undefined[sym.Value] = true
+	}
+	reported := make(map[string]bool)
+	for _, prod := range g.Productions {
+		for _, alt := range prod.Right {
+			for _, sym := range alt.Symbols {
+				if reported[sym.Value] || !undefined[sym.Value] {
+					continue
+				}
+				reported[sym.Value] = true
+				errs = append(errs, &UndefinedSymbolError{Symbol: sym, NonTerminal: prod.Left, Alternative: alt.Symbols, Pos: alt.Pos})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if productive := g.productive(nil); !productive[g.Start.Value] {
+		return g.emptyLanguage(nil, productive)
+	}
+	return nil
+}
+
+// productive
+// 以终结符为基础反复遍历产生式，求出能推出终结符串的非终结符，未定义的符号不能推出终结符串
+func (g *Grammar) productive(undefined []Symbol) map[string]bool {
+	lefts := make(map[string]bool)
+	for _, prod := range g.Productions {
+		lefts[prod.Left.Value] = true
+	}
+	for _, s := range undefined {
+		lefts[s.Value] = true
+	}
+	result := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, prod := range g.Productions {
+			if result[prod.Left.Value] {
+				continue
+			}
+			for _, alt := range prod.Right {
+				if g.derivesTerminals(alt, lefts, result) {
+					result[prod.Left.Value] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// derivesTerminals
+// 备选项中的每个符号是否都是终结符、ε或能推出终结符串的非终结符
+func (g *Grammar) derivesTerminals(alt Alternative, nonTerminals, productive map[string]bool) bool {
+	for _, sym := range alt.Symbols {
+		if nonTerminals[sym.Value] && !productive[sym.Value] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAlternatives
+// 产生式中nt是否至少有一个备选项
+func hasAlternatives(prods []Production, nt Symbol) bool {
+	for _, prod := range prods {
+		if prod.Left.Value == nt.Value {
+			return len(prod.Right) > 0
+		}
+	}
+	return false
+}
+
+// reachable
+// 从开始符出发，沿着产生式右部能到达的非终结符
+func (g *Grammar) reachable() map[string]bool {
+	alternatives := make(map[string][]Alternative)
+	for _, prod := range g.Productions {
+		alternatives[prod.Left.Value] = append(alternatives[prod.Left.Value], prod.Right...)
+	}
+	result := map[string]bool{g.Start.Value: true}
+	work := []string{g.Start.Value}
+	for len(work) > 0 {
+		nt := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, alt := range alternatives[nt] {
+			for _, sym := range alt.Symbols {
+				if _, ok := alternatives[sym.Value]; ok && !result[sym.Value] {
+					result[sym.Value] = true
+					work = append(work, sym.Value)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// UselessSymbols
+// 找出推不出终结符串、从开始符不可达和未定义的符号，结果按非终结符的声明顺序排列。
+// 应在GInit之前调用，MarkTerminals之后没有声明的标识符都被标为终结符
+func (g *Grammar) UselessSymbols() UselessSymbols {
+	var u UselessSymbols
+	u.Undefined = g.undefinedSymbols()
+	productive := g.productive(u.Undefined)
+	reachable := g.reachable()
+	for _, nt := range g.nonTerminalOrder() {
+		if !productive[nt.Value] {
+			u.Unproductive = append(u.Unproductive, nt)
+		}
+		if !reachable[nt.Value] {
+			u.Unreachable = append(u.Unreachable, nt)
+		}
+	}
+	return u
+}
+
+// RemoveUselessSymbols
+// 化简文法：先删除推不出终结符串的非终结符和含有它们（或未定义符号）的备选项，
+// 再删除从开始符不可达的非终结符。开始符推不出终结符串、化简会删掉开始符的所有产生式时
+// 返回EmptyLanguageError，列出无用的符号，文法保持不变
+func (g *Grammar) RemoveUselessSymbols() (Rewrite, error) {
+	rewrite := Rewrite{Name: "removeUselessSymbols"}
+	undefined := g.undefinedSymbols()
+	productive := g.productive(undefined)
+	if !productive[g.Start.Value] {
+		return rewrite, g.emptyLanguage(undefined, productive)
+	}
+	nonTerminals := make(map[string]bool)
+	for _, prod := range g.Productions {
+		nonTerminals[prod.Left.Value] = true
+	}
+	for _, s := range undefined {
+		nonTerminals[s.Value] = true
+	}
+	removed := NewSymbolSet()
+	var prods []Production
+	for _, prod := range g.Productions {
+		if !productive[prod.Left.Value] {
+			removed.Add(prod.Left)
+			rewrite.Changed = true
+			continue
+		}
+		kept := Production{Left: prod.Left}
+		for _, alt := range prod.Right {
+			if g.derivesTerminals(alt, nonTerminals, productive) {
+				kept.Right = append(kept.Right, alt)
+			} else {
+				rewrite.Changed = true
+			}
+		}
+		prods = append(prods, kept)
+	}
+	//开始符至少保留一个备选项，否则化简后的文法不能使用，保持文法不变
+	if !hasAlternatives(prods, g.Start) {
+		return rewrite, g.emptyLanguage(undefined, productive)
+	}
+	g.Productions = prods
+
+	reachable := g.reachable()
+	prods = nil
+	for _, prod := range g.Productions {
+		if reachable[prod.Left.Value] {
+			prods = append(prods, prod)
+		} else {
+			removed.Add(prod.Left)
+			rewrite.Changed = true
+		}
+	}
+	g.Productions = prods
+	rewrite.Removed = removed.Symbols()
+	rewrite.Productions = copyProductions(g.Productions)
+	return rewrite, nil
+}
@@ -0,0 +1,45 @@
+package ll1
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRemoveUselessSymbolsEmptyLanguage(t *testing.T) {
+	g, err := ParseGrammar("S -> A | <B> c ; A -> a A ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := g.String()
+	_, err = g.RemoveUselessSymbols()
+	var e *EmptyLanguageError
+	if !errors.As(err, &e) {
+		t.Fatalf("RemoveUselessSymbols() = %v, want EmptyLanguageError", err)
+	}
+	if got := SymbolsToString(e.Unproductive); got != "SA" {
+		t.Errorf("Unproductive = %s, want SA", got)
+	}
+	if len(e.Undefined) != 1 || e.Undefined[0].Value != "B" {
+		t.Errorf("Undefined = %v, want B", e.Undefined)
+	}
+	if g.String() != before {
+		t.Errorf("grammar changed to\n%s", g.String())
+	}
+}
+
+func TestRemoveUselessSymbolsKeepsStart(t *testing.T) {
+	g, err := ParseGrammar("S -> a | <B> c | A ; A -> a A ; C -> c ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrite, err := g.RemoveUselessSymbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Productions) != 1 || g.Productions[0].Left.Value != "S" || len(g.Productions[0].Right) != 1 {
+		t.Errorf("got\n%s, want S -> a", g.String())
+	}
+	if got := SymbolsToString(rewrite.Removed); got != "AC" {
+		t.Errorf("Removed = %s, want AC", got)
+	}
+}
@@ -0,0 +1,225 @@
+package ll1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// positionText
+// 输入中的位置，Column为0时位置未知，返回空串；Line为0时是单行输入
+func positionText(pos Position) string {
+	switch {
+	case pos.Column == 0:
+		return ""
+	case pos.Line == 0:
+		return fmt.Sprintf("column %d", pos.Column)
+	}
+	return pos.String()
+}
+
+// positionPrefix
+// 错误信息前的位置
+func positionPrefix(pos Position) string {
+	if text := positionText(pos); text != "" {
+		return text + ": "
+	}
+	return ""
+}
+
+// MissingStartError
+// 开始符没有产生式
+type MissingStartError struct {
+	Start Symbol
+}
+
+func (e *MissingStartError) Error() string {
+	return fmt.Sprintf("start symbol %s has no production", e.Start.Value)
+}
+
+// DuplicateProductionError
+// 同一个非终结符有多条产生式，或同一个备选项出现了两次；Alternative为nil时是左部重复，
+// Previous是第一次出现的位置
+type DuplicateProductionError struct {
+	NonTerminal Symbol
+	Alternative []Symbol
+	Pos         Position
+	Previous    Position
+}
+
+func (e *DuplicateProductionError) Error() string {
+	if e.Alternative == nil {
+		// 单行输入的产生式各自从第1列开始，列号没有意义
+		if e.Pos.Line == 0 {
+			return fmt.Sprintf("duplicate production for %s; combine the alternatives with |", e.NonTerminal.Value)
+		}
+		return fmt.Sprintf("%sduplicate production for %s, first defined at %s; combine the alternatives with |",
+			positionPrefix(e.Pos), e.NonTerminal.Value, e.Previous)
+	}
+	msg := fmt.Sprintf("%sduplicate alternative %s -> %s", positionPrefix(e.Pos), e.NonTerminal.Value, SymbolsToString(e.Alternative))
+	if prev := positionText(e.Previous); prev != "" {
+		msg += ", first defined at " + prev
+	}
+	return msg
+}
+
+// MixedEpsilonError
+// ε与其他符号写在同一个备选项中
+type MixedEpsilonError struct {
+	NonTerminal Symbol
+	Alternative Alternative
+}
+
+func (e *MixedEpsilonError) Error() string {
+	return fmt.Sprintf("%sε mixed with other symbols in %s -> %s, ε must be the only symbol of an alternative",
+		positionPrefix(e.Alternative.Pos), e.NonTerminal.Value, SymbolsToString(e.Alternative.Symbols))
+}
+
+// ReservedSymbolError
+// 输入结束符出现在产生式中，Alternative为nil时它是产生式的左部
+type ReservedSymbolError struct {
+	End         Symbol
+	NonTerminal Symbol
+	Alternative []Symbol
+	Pos         Position
+}
+
+func (e *ReservedSymbolError) Error() string {
+	where := "as a nonterminal"
+	if e.Alternative != nil {
+		where = fmt.Sprintf("in %s -> %s", e.NonTerminal.Value, SymbolsToString(e.Alternative))
+	}
+	return fmt.Sprintf("%s%s is reserved as the end marker and cannot be used %s; choose another end marker",
+		positionPrefix(e.Pos), e.End.Value, where)
+}
+
+// TerminalNameError
+// 引号括起来的终结符与某个非终结符同名，终结符和非终结符按名字区分，这样的终结符会被当作非终结符
+type TerminalNameError struct {
+	Terminal    Symbol
+	NonTerminal Symbol
+	Alternative []Symbol
+	Pos         Position
+}
+
+func (e *TerminalNameError) Error() string {
+	return fmt.Sprintf("%sterminal '%s' in %s -> %s has the same name as nonterminal %s; rename one of them",
+		positionPrefix(e.Pos), e.Terminal.Value, e.NonTerminal.Value, SymbolsToString(e.Alternative), e.Terminal.Value)
+}
+
+// EmptyProductionError
+// 产生式没有任何备选项（空的备选项是ε，不算在内）。
+// LeftRecursive为true时，这条产生式的备选项都是左递归的，消除左递归后不剩任何备选项
+type EmptyProductionError struct {
+	NonTerminal   Symbol
+	Pos           Position
+	LeftRecursive bool
+}
+
+func (e *EmptyProductionError) Error() string {
+	if e.LeftRecursive {
+		return fmt.Sprintf("%severy alternative of %s is left recursive, it derives no terminal string; add a non-recursive alternative",
+			positionPrefix(e.Pos), e.NonTerminal.Value)
+	}
+	return fmt.Sprintf("%sproduction for %s has no alternatives", positionPrefix(e.Pos), e.NonTerminal.Value)
+}
+
+// UndefinedSymbolError
+// 按非终结符使用、却没有产生式的符号，见Grammar.Declared；Pos是第一次出现的位置
+type UndefinedSymbolError struct {
+	Symbol      Symbol
+	NonTerminal Symbol
+	Alternative []Symbol
+	Pos         Position
+}
+
+func (e *UndefinedSymbolError) Error() string {
+	return fmt.Sprintf("%snonterminal %s in %s -> %s has no production",
+		positionPrefix(e.Pos), e.Symbol.Value, e.NonTerminal.Value, SymbolsToString(e.Alternative))
+}
+
+// InvalidEndMarkerError
+// 结束符不能是ε、eps，也不能含有空白
+type InvalidEndMarkerError struct {
+	End string
+}
+
+func (e *InvalidEndMarkerError) Error() string {
+	return fmt.Sprintf("invalid end marker %q, it must be a single symbol other than ε and eps", e.End)
+}
+
+// Validate
+// 检查文法是否可以初始化：开始符有产生式，每个非终结符只有一条产生式且至少有一个备选项，
+// 备选项不重复，ε单独作为备选项（空的备选项就是ε），输入结束符不出现在产生式中，
+// 引号括起来的终结符不与非终结符同名。返回所有问题，可以用errors.As取出具体的错误类型
+func (g *Grammar) Validate() error {
+	var errs []error
+	end := g.EndSymbol()
+	if end.Value == Epsilon.Value || end.Value == "eps" || strings.IndexFunc(end.Value, unicode.IsSpace) >= 0 {
+		errs = append(errs, &InvalidEndMarkerError{End: end.Value})
+	}
+	names := make(map[string]bool)
+	for _, prod := range g.Productions {
+		names[prod.Left.Value] = true
+	}
+	lefts := make(map[Symbol]Position)
+	for _, prod := range g.Productions {
+		if prod.Left.Value == end.Value && !prod.Left.IsEpsilon() {
+			errs = append(errs, &ReservedSymbolError{End: end, NonTerminal: prod.Left, Pos: prod.Pos})
+		}
+		if prev, ok := lefts[prod.Left]; ok {
+			errs = append(errs, &DuplicateProductionError{NonTerminal: prod.Left, Pos: prod.Pos, Previous: prev})
+		} else {
+			lefts[prod.Left] = prod.Pos
+		}
+		if len(prod.Right) == 0 {
+			errs = append(errs, &EmptyProductionError{NonTerminal: prod.Left, Pos: prod.Pos})
+		}
+		seen := make(map[string]Position)
+		for _, alt := range prod.Right {
+			if len(alt.Symbols) > 1 && hasEpsilon(alt.Symbols) {
+				errs = append(errs, &MixedEpsilonError{NonTerminal: prod.Left, Alternative: alt})
+			}
+			for _, s := range alt.Symbols {
+				if s.IsTerminal && s.Kind == GrammarSymbol && names[s.Value] {
+					errs = append(errs, &TerminalNameError{Terminal: s, NonTerminal: prod.Left, Alternative: alt.Symbols, Pos: alt.Pos})
+				}
+			}
+			for _, s := range alt.Symbols {
+				if s.Value == end.Value && !s.IsEpsilon() {
+					errs = append(errs, &ReservedSymbolError{End: end, NonTerminal: prod.Left, Alternative: alt.Symbols, Pos: alt.Pos})
+					break
+				}
+			}
+			values := []string{Epsilon.Value}
+			if len(alt.Symbols) > 0 {
+				values = make([]string, len(alt.Symbols))
+				for i, s := range alt.Symbols {
+					values[i] = s.Value
+				}
+			}
+			key := seqKey(values)
+			if prev, ok := seen[key]; ok {
+				errs = append(errs, &DuplicateProductionError{NonTerminal: prod.Left, Alternative: alt.Symbols, Pos: alt.Pos, Previous: prev})
+			} else {
+				seen[key] = alt.Pos
+			}
+		}
+	}
+	if _, ok := lefts[Symbol{Value: g.Start.Value, IsTerminal: false}]; !ok {
+		errs = append([]error{&MissingStartError{Start: g.Start}}, errs...)
+	}
+	return errors.Join(errs...)
+}
+
+// hasEpsilon
+// 符号串中是否有ε，也识别直接构造的值为ε的符号
+func hasEpsilon(symbols []Symbol) bool {
+	for _, s := range symbols {
+		if s.IsEpsilon() || s.Value == Epsilon.Value {
+			return true
+		}
+	}
+	return false
+}
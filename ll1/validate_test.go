@@ -0,0 +1,67 @@
+package ll1
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTerminalName(t *testing.T) {
+	g, err := ParseGrammar("S -> 'S' S | a ;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nameErr *TerminalNameError
+	if err := g.Validate(); !errors.As(err, &nameErr) {
+		t.Fatalf("Validate() = %v, want TerminalNameError", err)
+	}
+	if nameErr.Terminal.Value != "S" || nameErr.Pos.Line != 1 || nameErr.Pos.Column != 6 {
+		t.Errorf("got %+v, want terminal S at 1:6", nameErr)
+	}
+}
+
+func TestGInitRejectsUselessGrammars(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want func(error) bool
+	}{
+		{"undefined", "S -> a <T> ;", func(err error) bool {
+			var e *UndefinedSymbolError
+			return errors.As(err, &e) && e.Symbol.Value == "T"
+		}},
+		{"unproductive start", "S -> b S ;", func(err error) bool {
+			var e *EmptyLanguageError
+			return errors.As(err, &e) && e.Start.Value == "S"
+		}},
+		{"only left recursion", "S -> S a ;", func(err error) bool {
+			var e *EmptyLanguageError
+			return errors.As(err, &e)
+		}},
+		{"left recursive nonterminal", "S -> a | b A ; A -> A c ;", func(err error) bool {
+			var e *EmptyProductionError
+			return errors.As(err, &e) && e.NonTerminal.Value == "A" && e.LeftRecursive
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.GInit(); !tt.want(err) {
+				t.Errorf("GInit() = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateEmptyProduction(t *testing.T) {
+	g := &Grammar{
+		Start:       Symbol{Value: "S"},
+		Productions: []Production{{Left: Symbol{Value: "S"}}},
+	}
+	var e *EmptyProductionError
+	if err := g.Validate(); !errors.As(err, &e) || e.LeftRecursive {
+		t.Errorf("Validate() = %v, want EmptyProductionError", err)
+	}
+}
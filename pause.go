@@ -2,965 +2,291 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
-)
-
-type Symbol struct {
-	Value      string
-	IsTerminal bool
-}
-type Alternative struct {
-	Symbols []Symbol
-}
-type Production struct {
-	Left  Symbol
-	Right []Alternative
-}
-type Grammar struct {
-	Start       Symbol
-	Productions []Production
-	Nullable    map[string]bool
-	FirstSet    map[Symbol]map[Symbol]bool
-	FollowSet   map[Symbol]map[Symbol]bool
-	Predict     map[Symbol]map[Symbol]Production
-}
-
-// GetNonTerminals
-//获取输出的文法所有产生式的非终结符
-func (g *Grammar) GetNonTerminals() []Symbol {
-	nonTerminals := make(map[string]bool)
-
-	// 遍历所有产生式规则，将左部符号加入集合中
-	for _, production := range g.Productions {
-		nonTerminals[production.Left.Value] = true
-	}
-
-	// 将集合中的符号转换为切片并返回
-	result := make([]Symbol, 0, len(nonTerminals))
-	for nt := range nonTerminals {
-		result = append(result, Symbol{Value: nt, IsTerminal: false})
-	}
-	return result
-}
-
-// GetTerminals
-//获取输出的文法所有产生式的终结符
-func (g *Grammar) GetTerminals() []Symbol {
-	terminals := make(map[string]bool)
-	// 遍历产生式右部得到所有符号
-	for _, production := range g.Productions {
-		for _, alternative := range production.Right {
-			for _, sym := range alternative.Symbols {
-				// 如果符号不是非终结符，则将其加入终结符集合
-				terminals[sym.Value] = true
-			}
-		}
-	}
-
-	// 从终结符集合中删除非终结符
-	nonTerminals := g.GetNonTerminals()
-	for _, nt := range nonTerminals {
-		delete(terminals, nt.Value)
-	}
-	// 将集合中的终结符转换为切片并返回
-	result := make([]Symbol, 0, len(terminals))
-	for t := range terminals {
-		result = append(result, Symbol{Value: t, IsTerminal: true})
-	}
-	return result
-}
-
-// AllNullable
-//得到字符串的可空性
-func (g *Grammar) AllNullable(symbols []Symbol) bool {
-	for _, symbol := range symbols {
-		if symbol.IsTerminal || !g.Nullable[symbol.Value] {
-			return false
-		}
-	}
-	return true
-}
-
-// GetFirst
-//得到字符串的first集
-func (g Grammar) GetFirst(symbols []Symbol) []Symbol {
-	result := []Symbol{}
-	for _, symbol := range symbols {
-		if symbol.IsTerminal {
-			result = append(result, symbol)
-			break
-		} else {
-			first, exists := g.FirstSet[symbol]
-			if exists {
-				for s := range first {
-					if s.Value != "ε" {
-						result = append(result, s)
-					}
-				}
-			}
-		}
-	}
-	if g.AllNullable(symbols) {
-		result = append(result, Symbol{Value: "ε", IsTerminal: true})
-	}
-	return result
-}
-
-// Select
-//select集是对每个产生式进行处理
-//1.select(S->ab)=first(a)
-//select(S->AB)，若AB能得出->ε，则select(S->AB)={first(AB)-{ε}}∪follow(S)。反之，select(S->AB)=first(AB)
-func (g Grammar) Select(left Symbol, right []Symbol) []Symbol {
-	result := []Symbol{}
-	if right[0].IsTerminal {
-		result = append(result, right[0])
-		return result
-	}
-	if g.AllNullable(right) {
-		for s := range g.FollowSet[left] {
-			result = append(result, s)
-		}
-		for _, s := range g.GetFirst(right) {
-			if s.Value != "v" {
-				result = append(result, s)
-			}
-		}
+	"unicode/utf8"
 
-	} else {
-		for _, s := range g.GetFirst(right) {
-			result = append(result, s)
-		}
-	}
-	return result
-}
+	"github.com/wrilove/Table-drives-LL-1-parser/codegen"
+	"github.com/wrilove/Table-drives-LL-1-parser/export"
+	"github.com/wrilove/Table-drives-LL-1-parser/importer"
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
 
-//分析栈和字符栈，懒得写个栈结构了，用切片将就吧，characterStack默认切片首元素为栈顶，尾元素为栈底。analysisStack默认切片首元素为栈底，尾元素为栈顶
-//在循环中，检查分析栈顶的符号。如果它是一个终结符，请检查它是否与 characterStack 的栈顶元素匹配。如果匹配，则将两个栈的栈顶元素弹出；如果不匹配，则输出错误消息并返回。如果匹配且都为终止符#，则匹配成功
-//如果栈顶符号是一个非终结符，请在预测分析表（g.Predict）中查找与当前非终结符和 characterStack 栈顶元素对应的产生式。将产生式右侧的符号逆序压入 analysisStack
-func (g Grammar) parse(strs string) {
-	fmt.Printf("%s的分析过程\n", strs)
-	//计数器，分析的步骤
-	count := 1
-	//分析栈和字符栈的初始化
-	var characterStack []string
-	for _, s := range strs {
-		characterStack = append(characterStack, string(s))
-	}
-	characterStack = append(characterStack, "#")
-	var analysisStack []Symbol
-	analysisStack = append(analysisStack, Symbol{"#", true})
-	analysisStack = append(analysisStack, g.Start)
-	// 使用 tabwriter 对输出进行对齐
-	w := tabwriter.NewWriter(os.Stdout, 8, 0, 2, ' ', 0)
-	parseContinue := true
-	for parseContinue {
-		topAnalysis := analysisStack[len(analysisStack)-1]
-		topCharacter := characterStack[0]
-		printStep(w, count, analysisStack, characterStack)
-		w.Flush()
-		fmt.Printf("\t")
-		if topAnalysis.IsTerminal {
-			if topAnalysis.Value == "#" {
-				fmt.Println("输入的字符串分析成功.")
-				parseContinue = false
-			}
-			if topAnalysis.Value == topCharacter {
-				fmt.Printf("匹配成功%s.\n", topAnalysis.Value)
-				analysisStack = analysisStack[:len(analysisStack)-1]
-				characterStack = characterStack[1:]
-			} else {
-				fmt.Printf("匹配失败.\n")
-				parseContinue = false
-			}
-		} else {
-			prod, exist := g.Predict[topAnalysis][Symbol{topCharacter, true}]
-			if exist {
-				analysisStack = analysisStack[:len(analysisStack)-1]
-				newSymbols := []Symbol{}
-				for _, s := range prod.Right[0].Symbols {
-					newSymbols = append(newSymbols, s)
-				}
-				for len(newSymbols) > 0 {
-					if newSymbols[len(newSymbols)-1].Value != "ε" {
-						analysisStack = append(analysisStack, newSymbols[len(newSymbols)-1])
-					}
-					newSymbols = newSymbols[:len(newSymbols)-1]
-				}
-				//打印使用的产生式
-				fmt.Printf("使用产生式 %s -> ", topAnalysis.Value)
-				for _, sym := range prod.Right[0].Symbols {
-					fmt.Printf("%s ", sym.Value)
-				}
-				fmt.Println()
-			} else {
-				fmt.Printf("匹配失败.\n")
-				parseContinue = false
-			}
-		}
-		count++
-	}
-}
-func printStep(w *tabwriter.Writer, step int, analysisStack []Symbol, characterStack []string) {
-	fmt.Fprintf(w, "%d\t", step)
-	for _, a := range analysisStack {
-		fmt.Fprintf(w, "%s", a.Value)
-	}
-	fmt.Fprint(w, "\t")
-	for _, c := range characterStack {
-		fmt.Fprintf(w, "%s", c)
-	}
-}
 func main() {
-	prods := make([]Production, 0)
-	reader := bufio.NewReader(os.Stdin)
+	// 第一个参数是子命令时按非交互的方式运行
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+	compact := flag.Bool("compact", false, "treat every character of a production as one symbol, e.g. S->AaS|d")
+	format := flag.String("format", "", "export the sets and predict table as "+strings.Join(export.Formats, ", ")+" instead of the interactive output")
+	output := flag.String("o", "", "write the exported tables to this file instead of stdout")
+	gen := flag.String("gen", "", "generate a standalone table-driven Go parser into this file")
+	pkg := flag.String("package", "parser", "package name of the generated parser")
+	style := flag.String("style", "table", "style of the generated parser: table or descent")
+	reduce := flag.Bool("reduce", false, "remove unproductive and unreachable symbols before computing the sets")
+	end := flag.String("end", "", "end-of-input marker, e.g. $ (default: %end in the grammar file, or "+ll1.DefaultEndMarker+")")
+	maxK := flag.Int("k", 1, "if the grammar is not LL(1), look for the smallest k <= this limit that makes it LL(k)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-compact] [-reduce] [-end marker] [-k limit] [-format fmt [-o file]] [-gen file.go [-package name] [-style table|descent]] [grammar-file]\n", os.Args[0])
+		flag.PrintDefaults()
+		commandUsage()
+	}
+	flag.Parse()
 
-	// 输入开始符
-	startSymbolStruct := Symbol{}
-	for {
-		fmt.Print("Enter start symbol: ")
-		startSymbol, _ := reader.ReadString('\n')
-		startSymbol = strings.TrimSpace(startSymbol)
-		if len(startSymbol) == 1 {
-			startSymbolStruct = Symbol{Value: startSymbol, IsTerminal: false}
-			break
-		} else {
-			fmt.Println("Start symbol must be a single character.")
-		}
-	}
-	// 输入产生式
-	for {
-		fmt.Print("Enter production (or q to quit): ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		if input == "q" {
-			break
-		}
-		parts := strings.Split(input, "->")
-		if len(parts) != 2 {
-			fmt.Println("Invalid production")
-			continue
-		}
-		left := strings.TrimSpace(parts[0])
-		rightPorts := strings.Split(strings.TrimSpace(parts[1]), "|")
-
-		alternatives := make([]Alternative, len(rightPorts))
-		for i, rightStr := range rightPorts {
-			trimmedRightStr := strings.TrimSpace(rightStr)
-			symbols := []Symbol{}
-			for _, symbolRune := range trimmedRightStr {
-				// 假设文法输入时将所有符号视为非终结符
-				symbolStr := string(symbolRune)
-				symbols = append(symbols, Symbol{Value: symbolStr, IsTerminal: false})
-			}
-			alternatives[i] = Alternative{Symbols: symbols}
-		}
-
-		leftSymbol := Symbol{Value: left, IsTerminal: false}
-		prod := Production{Left: leftSymbol, Right: alternatives}
-		prods = append(prods, prod)
-	}
-
-	// 创建文法 g
-	g := Grammar{
-		Start:       startSymbolStruct,
-		Productions: prods,
-		Nullable:    nil,
-		FirstSet:    nil,
-		FollowSet:   nil,
-		Predict:     nil,
+	reader := bufio.NewReader(os.Stdin)
+	var g *ll1.Grammar
+	switch flag.NArg() {
+	case 0:
+		g = readGrammar(reader, *compact)
+	case 1:
+		var err error
+		g, err = importer.LoadFile(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *end != "" {
+		g.End = *end
+	}
+	if err := g.Validate(); err != nil {
+		printErrors(flag.Arg(0), err)
+		os.Exit(1)
+	}
+	g.RemoveUseless = *reduce
+	if *gen != "" {
+		if err := generate(g, *gen, *style, codegen.Options{Package: *pkg}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *format != "" {
+		isLL1, err := exportTables(g, *format, *output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !isLL1 {
+			fmt.Fprintln(os.Stderr, "grammar is not LL(1), see the conflicts in the exported tables")
+			os.Exit(1)
+		}
+		return
 	}
 	fmt.Println()
-	g.PrintGrammar()
+	PrintGrammar("Input grammar:", g)
 	fmt.Println()
-	if g.GInit() {
+	ok := GInit(g)
+	parse := g.Parse
+	if !ok && *maxK > 1 {
+		if table := MinimalK(g, *maxK); table != nil {
+			ok = true
+			parse = table.Parse
+		}
+	}
+	if ok {
 		for {
 			fmt.Print("Please enter the string you want to parse (or q to quit): ")
-			input, _ := reader.ReadString('\n')
+			input, err := reader.ReadString('\n')
 			input = strings.TrimSpace(input)
-			if input == "q" {
+			if input == "q" || err != nil && input == "" {
 				break
 			}
-			input = strings.TrimSpace(input)
 			fmt.Println()
-			g.parse(input)
+			tokens, err := g.Tokenize(input)
+			if err != nil {
+				fmt.Println("Lexical error:", err)
+				fmt.Println()
+				continue
+			}
+			result := parse(tokens)
+			PrintParse(input, result)
+			if result.Accepted {
+				fmt.Println()
+				PrintTree(result.Tree)
+			}
 			fmt.Println()
 		}
 	}
 }
 
-//初始化专区
-
 // GInit
-//对于一个输入了开始符和产生式集的文法进行初始化，得到他的Nullable，FirstSet，FollowSet，Predict，并判断是否为LL1文法，返回结果
-func (g *Grammar) GInit() bool {
-	//更新符号的 IsTerminal 字段
-	terminals := g.GetTerminals()
-	terminalSet := make(map[string]bool)
-	for _, t := range terminals {
-		terminalSet[t.Value] = true
+// 调用库中的GInit完成初始化，并打印无用的符号、改写后的文法、各个集合、LL1判断结果与预测分析表
+func GInit(g *ll1.Grammar) bool {
+	if useless := g.UselessSymbols(); !useless.Empty() {
+		PrintUselessSymbols(useless)
+		fmt.Println()
 	}
-	for i, production := range g.Productions {
-		for j, alternative := range production.Right {
-			for k, sym := range alternative.Symbols {
-				if terminalSet[sym.Value] {
-					g.Productions[i].Right[j].Symbols[k].IsTerminal = true
-				}
-			}
+	isLL1, err := g.GInit()
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	for _, rewrite := range g.Rewrites {
+		if rewrite.Changed {
+			PrintRewrite(rewrite)
+			fmt.Println()
 		}
 	}
-	//提取左公因子与消除左递归
-	g.extractCommonFactors()
-	g.eliminateDirectLeftRecursion()
-	g.PrintNonTerminals()
-	g.PrintTerminals()
+	PrintNonTerminals(g)
+	PrintTerminals(g)
 	fmt.Println()
 
-	//初始化nullable表，first表，follow表
-	g.initializeNullable()
-	g.PrintNullableTable()
+	PrintNullableTable(g)
 	fmt.Println()
 
-	g.initializeFirstSet()
-	g.PrintFirstSet()
+	PrintFirstSet(g)
 	fmt.Println()
 
-	g.initializeFollowSet()
-	g.PrintFollowSet()
+	PrintFollowSet(g)
 	fmt.Println()
-	//如果是LL1文法则继续否则结束
-	if g.isLL1() {
-		fmt.Println()
-		g.initializePredict()
-		g.PrintPredict()
-		return true
-	}
-	return false
-}
-
-//A→Aα1|Aα2|…|Aαm|β1|β2|…|βn
-//消除后为
-//A→(β1|β2|…|βn)A’
-//A’→(α1|α2|…|αm)A’|ε
-func (g *Grammar) eliminateDirectLeftRecursion() {
-	isReForm := false
-	for _, nt1 := range g.GetNonTerminals() {
-		// 遍历每个非终结符nt1
-		for j, prod := range g.Productions {
-			// 遍历产生式找到该非终结符
-			if prod.Left == nt1 {
-				alpha := make([]Alternative, 0)
-				beta := make([]Alternative, 0)
-
-				// 查找是否有左递归
-				for _, alt := range prod.Right {
-					if len(alt.Symbols) > 0 && alt.Symbols[0].Value == nt1.Value {
-						alpha = append(alpha, Alternative{Symbols: alt.Symbols[1:]})
-					} else {
-						beta = append(beta, alt)
-					}
-				}
 
-				// 有左递归，需要消除
-				if len(alpha) > 0 {
-					isReForm = true
-					newNt1 := Symbol{Value: nt1.Value + "'", IsTerminal: false}
-
-					// 更新原有产生式
-					updatedRight := make([]Alternative, 0)
-					for _, b := range beta {
-						updatedRight = append(updatedRight, Alternative{Symbols: append(b.Symbols, newNt1)})
-					}
-					g.Productions[j].Right = updatedRight
-
-					// 添加新产生式
-					newRight := make([]Alternative, 0)
-					for _, a := range alpha {
-						newRight = append(newRight, Alternative{Symbols: append(a.Symbols, newNt1)})
-					}
-					newRight = append(newRight, Alternative{Symbols: []Symbol{{Value: "ε", IsTerminal: true}}})
-					g.Productions = append(g.Productions, Production{Left: newNt1, Right: newRight})
-				}
-			}
-		}
-	}
-
-	if isReForm {
-		fmt.Println("eliminateDirectLeftRecursion grammar:")
-		for _, prod := range g.Productions {
-			rightParts := make([]string, len(prod.Right))
-			for i, alt := range prod.Right {
-				symbols := make([]string, len(alt.Symbols))
-				for j, sym := range alt.Symbols {
-					symbols[j] = sym.Value
-				}
-				rightParts[i] = strings.Join(symbols, "")
-			}
-			fmt.Printf("%s -> %s\n", prod.Left.Value, strings.Join(rightParts, "|"))
-		}
+	PrintLL1(g)
+	if isLL1 {
 		fmt.Println()
+		PrintPredict(g)
 	}
+	return isLL1
 }
 
-//提取公因子：将产生式中的公共前缀提取出来，简化文法。
-func (g *Grammar) extractCommonFactors() {
-	flag := false
-	// 计数器，用于生成新的非终结符
-	newNonTerminalCounter := 0
-	for i, production := range g.Productions {
-		// 新的备选项列表，用于存储提取公共前缀后的备选项
-		newAlternatives := []Alternative{}
-		// 创建一个映射，用于将具有相同前缀的备选项归类到一起。
-		// 键是前缀（即符号的值），值是具有相同前缀的备选项列表。
-		prefixMap := make(map[Symbol][]Alternative)
-		// 遍历产生式的每个备选项,，存入前缀映射
-		for _, alternative := range production.Right {
-			if len(alternative.Symbols) > 0 {
-				// 取备选项的第一个符号作为键
-				firstSymbol := alternative.Symbols[0]
-				// 将具有相同前缀的备选项添加到映射中
-				if _, ok := prefixMap[firstSymbol]; !ok {
-					prefixMap[firstSymbol] = []Alternative{}
-				}
-				prefixMap[firstSymbol] = append(prefixMap[firstSymbol], alternative)
-			}
-		}
-		// 遍历具有相同前缀的备选项组
-		for _, alternatives := range prefixMap {
-			// 如果具有相同前缀的备选项数量大于 1
-			if len(alternatives) > 1 {
-				flag = true
-				// 查找多个备选项的最长公共前缀
-				commonPrefix := findLongestCommonPrefix(alternatives)
-				// 生成一个新的非终结符，例如 "A1"、"A2" 等
-				newNonTerminalCounter++
-				newValue := fmt.Sprintf("A%d", newNonTerminalCounter)
-				newNonTerminal := Symbol{
-					Value:      newValue,
-					IsTerminal: false,
-				}
-				// 移除原来备选项中的公共前缀
-				newRight := removeCommonPrefix(alternatives, commonPrefix)
-				// 创建一个新的产生式，左侧是新的非终结符，右侧是移除公共前缀后的备选项列表
-				newProduction := Production{
-					Left:  newNonTerminal,
-					Right: newRight,
-				}
-				// 将新的产生式添加到文法的产生式列表中
-				g.Productions = append(g.Productions, newProduction)
-				// 创建一个新的备选项，包括公共前缀和新的非终结符
-				var newAlternative Alternative
-				commonPrefixCopy := make([]Symbol, len(commonPrefix))
-				copy(commonPrefixCopy, commonPrefix)
-				newAlternative.Symbols = commonPrefixCopy
-				newAlternative.Symbols = append(newAlternative.Symbols, newNonTerminal)
-				// 将新的备选项添加到新的备选项列表中
-				newAlternatives = append(newAlternatives, newAlternative)
-			} else {
-				// 如果只有一个备选项，直接将其添加到新的备选项列表中
-				newAlternatives = append(newAlternatives, alternatives[0])
-			}
-		}
-		// 更新产生式的备选项列表
-		g.Productions[i].Right = newAlternatives
-	}
-	if flag {
-		fmt.Println("extractCommonFactors grammar:")
-		for _, prod := range g.Productions {
-			rightParts := make([]string, len(prod.Right))
-			for i, alt := range prod.Right {
-				symbols := make([]string, len(alt.Symbols))
-				for j, sym := range alt.Symbols {
-					symbols[j] = sym.Value
-				}
-				rightParts[i] = strings.Join(symbols, "")
-			}
-			fmt.Printf("%s -> %s\n", prod.Left.Value, strings.Join(rightParts, "|"))
-		}
-		fmt.Println()
-	}
-}
-func findLongestCommonPrefix(alternatives []Alternative) []Symbol {
-	if len(alternatives) == 0 {
+// MinimalK
+// 文法不是LL1文法时寻找不超过limit的最小k，打印结果并返回LL(k)分析表，找不到时返回nil
+func MinimalK(g *ll1.Grammar, limit int) *ll1.LLkTable {
+	fmt.Println()
+	report := g.MinimalK(limit)
+	PrintLLk(g, report)
+	if !report.Deterministic {
 		return nil
 	}
-	// 获取第一个备选项的符号列表作为参考
-	reference := alternatives[0].Symbols
-	// 初始化最长公共前缀的长度为参考备选项的长度
-	maxLength := len(reference)
-	// 遍历其余的备选项
-	for _, alternative := range alternatives[1:] {
-		i := 0
-		// 比较当前备选项与参考备选项的符号，找出公共前缀的长度存为i
-		for i < len(alternative.Symbols) && i < maxLength {
-			if alternative.Symbols[i] != reference[i] {
-				break
-			}
-			i++
-		}
-
-		// 更新最长公共前缀的长度
-		if i < maxLength {
-			maxLength = i
-		}
-	}
-
-	// 返回最长公共前缀的符号列表
-	return reference[:maxLength]
+	return report.Table
 }
-func removeCommonPrefix(alternatives []Alternative, prefix []Symbol) []Alternative {
-	// 创建一个新的备选项列表，用于存储移除公共前缀后的备选项
-	newAlternatives := []Alternative{}
-	// 获取公共前缀的长度
-	prefixLength := len(prefix)
-	// 遍历原始备选项列表
-	for _, alternative := range alternatives {
-		// 创建一个新的符号列表，用于存储移除公共前缀后的符号
-		newSymbols := []Symbol{}
 
-		// 如果原始符号列表的长度大于公共前缀的长度，则从原始符号列表中移除公共前缀
-		if len(alternative.Symbols) > prefixLength {
-			newSymbols = alternative.Symbols[prefixLength:]
-		}
-
-		// 将移除公共前缀后的符号列表添加到新的备选项中
-		newAlternative := Alternative{Symbols: newSymbols}
-
-		// 将新的备选项添加到新的备选项列表中
-		newAlternatives = append(newAlternatives, newAlternative)
+// exportTables
+// 初始化文法后按format导出各个集合和预测分析表，path为空时写到标准输出；
+// 文法不是LL1文法时仍然导出（冲突在导出的结果中列出），返回false
+func exportTables(g *ll1.Grammar, format, path string) (bool, error) {
+	isLL1, err := g.GInit()
+	if err != nil {
+		return false, err
 	}
-
-	// 返回移除公共前缀后的新的备选项列表
-	return newAlternatives
-}
-
-//以S->ε为基础循环遍历产生式，找到所有能推导出空串的非终结符存入空串表
-func (g *Grammar) initializeNullable() {
-	g.Nullable = make(map[string]bool)
-	// 初始化，将所有非终结符设置为不可空
-	for _, production := range g.Productions {
-		// 设置非终结符的可空性为 false
-		g.Nullable[production.Left.Value] = false
+	// 先写到缓冲区，格式错误时不会留下空文件
+	var buf bytes.Buffer
+	if err := export.NewTables(g).Write(&buf, format); err != nil {
+		return false, err
 	}
-
-	// 反复遍历产生式，直到没有新的可空非终结符被添加
-	// 使用一个布尔值变量 `changed` 来跟踪是否有新的可空非终结符被找到
-	changed := true
-	for changed {
-		// 设置 `changed` 为 false，表示在本轮遍历中尚未找到新的可空非终结符
-		changed = false
-
-		// 遍历产生式
-		for _, production := range g.Productions {
-			// 如果当前非终结符尚未被标记为可空
-			if !g.Nullable[production.Left.Value] {
-				// 遍历产生式右侧的替代项
-				for _, alternative := range production.Right {
-					// 假定当前替代项可导出空串
-					nullable := true
-
-					// 遍历替代项中的符号
-					for _, symbol := range alternative.Symbols {
-						// 如果符号是终结符，或者符号是一个不可空的非终结符
-						if symbol.Value == "ε" {
-							//空串，直接跳出
-							break
-						}
-						if symbol.IsTerminal || !g.Nullable[symbol.Value] {
-							// 将 `nullable` 设置为 false，表示当前替代项不可导出空串
-							nullable = false
-							break
-						}
-					}
-					// 如果当前替代项可导出空串
-					if nullable {
-						// 将左侧非终结符标记为可空
-						g.Nullable[production.Left.Value] = true
-						// 将 `changed` 设置为 true，表示在本轮遍历中找到了新的可空非终结符
-						changed = true
-						// 跳出替代项遍历循环
-						break
-					}
-				}
-			}
-		}
-	}
-}
-
-//终结符的first集为自己
-//非终结符的first集并入（除ε之外）
-//如果可空继续判断下一个字符，如果都可空则加入ε
-func (g *Grammar) initializeFirstSet() {
-	g.FirstSet = make(map[Symbol]map[Symbol]bool)
-
-	// 遍历产生式，初始化每个符号的 First 集
-	for _, production := range g.Productions {
-		if _, ok := g.FirstSet[production.Left]; !ok {
-			g.FirstSet[production.Left] = make(map[Symbol]bool)
-		}
-		for _, alternative := range production.Right {
-			for _, symbol := range alternative.Symbols {
-				if _, ok := g.FirstSet[symbol]; !ok {
-					g.FirstSet[symbol] = make(map[Symbol]bool)
-				}
-			}
-		}
-	}
-
-	// 将终结符添加到它们自己的 First 集中
-	for symbol, symbolFirstSet := range g.FirstSet {
-		if symbol.Value != "ε" && symbol.IsTerminal {
-			symbolFirstSet[symbol] = true
-		}
-	}
-
-	// 计算非终结符的 First 集
-	changed := true // 反复遍历产生式，直到没有新的符号被添加到 First 集
-	for changed {
-		changed = false
-		for _, production := range g.Productions {
-			left := production.Left
-			for _, alternative := range production.Right {
-				nullable := true
-				for _, symbol := range alternative.Symbols {
-					// 如果符号是非终结符
-					if !symbol.IsTerminal {
-						// 将 symbol 的 First 集合并到 left 的 First 集中
-						for s, exist := range g.FirstSet[symbol] {
-							if exist && s.Value != "ε" && !g.FirstSet[left][s] {
-								g.FirstSet[left][s] = true
-								changed = true
-							}
-						}
-						// 如果当前符号是不可空的，跳出循环
-						if !g.Nullable[symbol.Value] {
-							nullable = false
-							break
-						}
-					} else {
-						// 如果符号是终结符，将其添加到 left 的 First 集中，并跳出循环
-						if !g.FirstSet[left][symbol] {
-							g.FirstSet[left][symbol] = true
-							changed = true
-						}
-						nullable = false
-						break
-					}
-				}
-				// 如果所有符号都是可空的，则将 "ε" 添加到 left 的 First 集中
-				if nullable {
-					if !g.FirstSet[left][Symbol{Value: "ε", IsTerminal: false}] {
-						g.FirstSet[left][Symbol{Value: "ε", IsTerminal: false}] = true
-						changed = true
-					}
-				}
-			}
-		}
-	}
-}
-
-//开始符号的follow应该有输入结束语#
-//终结符没有follow集
-//对于非终结符 A，如果 A 后面紧跟着一个终结符 a，则将 a 添加到 A 的 Follow 集中。
-//对于非终结符 A，如果 A 后面紧跟着一个非终结符 B，则将 B 的 First 集（不包括 "ε"）中的所有符号添加到 A 的 Follow 集中。
-//对于非终结符 A，如果 A 后面紧跟着一个非终结符 B，且 B 可导出空串（"ε"），则将产生式左侧非终结符的 Follow 集中的所有符号添加到 A 的 Follow 集中。
-func (g *Grammar) initializeFollowSet() {
-	g.FollowSet = make(map[Symbol]map[Symbol]bool)
-	// 初始化非终结符的 Follow 集
-	for _, production := range g.Productions {
-		left := production.Left
-		if _, ok := g.FollowSet[left]; !ok {
-			g.FollowSet[left] = make(map[Symbol]bool)
-		}
-	}
-	// 将文法开始符号的 Follow 集设为 { # }，表示输入结束符号
-	g.FollowSet[g.Start] = map[Symbol]bool{Symbol{Value: "#", IsTerminal: true}: true}
-	// 反复遍历产生式，直到 Follow 集不再发生变化
-	changed := true
-	for changed {
-		changed = false
-		for _, production := range g.Productions {
-			left := production.Left
-			//遍历产生式，得到symbol为非终结符A
-			for _, alternative := range production.Right {
-				for i, symbol := range alternative.Symbols {
-					if !symbol.IsTerminal {
-						for j := i + 1; j < len(alternative.Symbols); j++ {
-							nextSymbol := alternative.Symbols[j]
-							if nextSymbol.IsTerminal {
-								//终结符，加入
-								if _, exists := g.FollowSet[symbol][nextSymbol]; !exists {
-									g.FollowSet[symbol][nextSymbol] = true
-									changed = true
-								}
-								break
-							} else {
-								//将 nextSymbol 的 First 集合加入 symbol 的 Follow 集合
-								for firstSymbol := range g.FirstSet[nextSymbol] {
-									if firstSymbol.Value != "ε" {
-										if _, exists := g.FollowSet[symbol][firstSymbol]; !exists {
-											g.FollowSet[symbol][firstSymbol] = true
-											changed = true
-										}
-									}
-								}
-								// 如果 nextSymbol 可以为空，则继续往后处理
-								if !g.Nullable[nextSymbol.Value] {
-									break
-								}
-							}
-						}
-						// 如果非终结符 A 后面的所有非终结符都可以为空，则将产生式左侧非终结符的 Follow 集中的所有符号添加到 A 的 Follow 集中
-						if g.AllNullable(alternative.Symbols[i+1:]) {
-							for followSymbol := range g.FollowSet[left] {
-								if _, exists := g.FollowSet[symbol][followSymbol]; !exists {
-									g.FollowSet[symbol][followSymbol] = true
-									changed = true
-								}
-							}
-						}
-					}
-				}
-			}
-		}
+	if path == "" {
+		_, err = buf.WriteTo(os.Stdout)
+	} else {
+		err = os.WriteFile(path, buf.Bytes(), 0o644)
 	}
+	return isLL1, err
 }
 
-//Predict     map[Symbol]map[Symbol]Production
-//构造分析表的第一个元素为左边的非终结符，第二个元素为上面的终结符
-//对文法G的每个产生式A->α 执行如下步骤：
-//（1）对每个a∈First(α)，把 A->α 加入M[A,a]
-//（2）若 ε∈First(α)，则对任何b∈Follow(A) ,把 A-> ε加至M[A,b]中
-//得到构造表Predict 存储了M[A,b]
-func (g *Grammar) initializePredict() {
-	var epsilon Symbol = Symbol{
-		Value:      "ε",
-		IsTerminal: true,
+// generate
+// 初始化文法并按style生成表驱动或递归下降的分析程序，文法不是LL1文法时返回冲突
+func generate(g *ll1.Grammar, path, style string, opts codegen.Options) error {
+	var generator func(*ll1.Grammar, codegen.Options) ([]byte, error)
+	switch style {
+	case "table":
+		generator = codegen.GenerateTable
+	case "descent":
+		generator = codegen.GenerateDescent
+	default:
+		return fmt.Errorf("unknown parser style %q, expected table or descent", style)
 	}
-	g.Predict = make(map[Symbol]map[Symbol]Production)
-	//初始化
-	for _, nonterminal := range g.GetNonTerminals() {
-		g.Predict[nonterminal] = make(map[Symbol]Production)
+	isLL1, err := g.GInit()
+	if err != nil {
+		return err
 	}
-	for _, prod := range g.Productions {
-		for _, alter := range prod.Right {
-			//对于每个产生式prod.left->alter
-			for _, s := range g.GetFirst(alter.Symbols) {
-				g.Predict[prod.Left][s] = Production{
-					Left:  prod.Left,
-					Right: []Alternative{alter},
-				}
-			}
-			if findString(g.GetFirst(alter.Symbols), "ε") {
-				for s := range g.FollowSet[prod.Left] {
-					g.Predict[prod.Left][s] = Production{
-						Left: prod.Left,
-						Right: []Alternative{
-							{[]Symbol{epsilon}},
-						},
-					}
-				}
-			}
-		}
+	if !isLL1 {
+		return fmt.Errorf("grammar is not LL(1): %v", g.UnresolvedConflicts()[0])
 	}
-}
-func findString(strs []Symbol, s string) bool {
-	for _, str := range strs {
-		if str.Value == s {
-			return true
-		}
+	src, err := generator(g, opts)
+	if err != nil {
+		return err
 	}
-	return false
+	return os.WriteFile(path, src, 0o644)
 }
 
-//isLL1
-//对于每个非终结符A，检查每对产生式P1, P2是否存在以下情况
-//1. P1和P2都以相同的终结符开始，这是FIRST集冲突
-//2. P1的FIRST集包含空串，P2的FIRST集与A的FOLLOW集有交集，这是FOLLOW集冲突
-func (g Grammar) isLL1() bool {
-	fmt.Println(" LL1 grammar or not:")
-	flag := false
-	for _, prod := range g.Productions {
-		if len(prod.Right) > 1 {
-			for i := 0; i < len(prod.Right); i++ {
-				for j := i + 1; j < len(prod.Right); j++ {
-					select1 := g.Select(prod.Left, prod.Right[i].Symbols)
-					select2 := g.Select(prod.Left, prod.Right[j].Symbols)
-					if hasIntersection(select1, select2) {
-						flag = true
-						fmt.Printf("select(%s)∩select(%s)!=Ø\n", symbolsToString(prod.Right[i].Symbols), symbolsToString(prod.Right[j].Symbols))
-					} else {
-						fmt.Printf("select(%s)∩select(%s)=Ø\n", symbolsToString(prod.Right[i].Symbols), symbolsToString(prod.Right[j].Symbols))
-					}
-				}
-			}
-		}
-	}
-	if flag {
-		fmt.Println("The grammar you entered is not the LL1 grammar")
-	} else {
-		fmt.Println("The grammar you entered is  the LL1 grammar,please continue")
-	}
-	return !flag
-}
-func hasIntersection(slice1, slice2 []Symbol) bool {
-	elementMap := make(map[Symbol]bool)
-	for _, elem := range slice1 {
-		elementMap[elem] = true
+// printErrors
+// 逐行打印Validate返回的错误，从文件读入文法时在前面加上文件名
+func printErrors(path string, err error) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
 	}
-	// 遍历slice2，查找是否存在于elementMap中的元素
-	for _, elem := range slice2 {
-		if _, found := elementMap[elem]; found {
-			return true
+	for _, e := range errs {
+		msg := e.Error()
+		switch {
+		case path == "":
+		case msg != "" && msg[0] >= '0' && msg[0] <= '9':
+			// 带有行号的错误写成 file:line:column: msg
+			msg = path + ":" + msg
+		default:
+			msg = path + ": " + msg
 		}
+		fmt.Fprintln(os.Stderr, msg)
 	}
-	return false
-}
-func symbolsToString(symbols []Symbol) string {
-	values := make([]string, len(symbols))
-	for i, symbol := range symbols {
-		values[i] = symbol.Value
-	}
-	return strings.Join(values, "")
 }
 
-//打印专区
+// readGrammar
+// 交互式地输入开始符和产生式
+func readGrammar(reader *bufio.Reader, compact bool) *ll1.Grammar {
+	prods := make([]ll1.Production, 0)
 
-func (g *Grammar) PrintGrammar() {
-	fmt.Println("Input grammar:")
-	for _, prod := range g.Productions {
-		rightParts := make([]string, len(prod.Right))
-		for i, alt := range prod.Right {
-			symbols := make([]string, len(alt.Symbols))
-			for j, sym := range alt.Symbols {
-				symbols[j] = sym.Value
+	// 输入开始符
+	startSymbolStruct := ll1.Symbol{}
+	for {
+		fmt.Print("Enter start symbol: ")
+		startSymbol, _ := reader.ReadString('\n')
+		startSymbol = strings.TrimSpace(startSymbol)
+		if compact {
+			if utf8.RuneCountInString(startSymbol) == 1 {
+				startSymbolStruct = ll1.Symbol{Value: startSymbol, IsTerminal: false}
+				break
 			}
-			rightParts[i] = strings.Join(symbols, "")
-		}
-		fmt.Printf("%s -> %s\n", prod.Left.Value, strings.Join(rightParts, "|"))
-	}
-}
-func (g *Grammar) PrintNonTerminals() {
-	nonTerminals := g.GetNonTerminals()
-	fmt.Println("Nonterminals:")
-	printSymbolSlice(nonTerminals)
-}
-func (g *Grammar) PrintTerminals() {
-	terminals := g.GetTerminals()
-	fmt.Println("Terminals:")
-	printSymbolSlice(terminals)
-}
-func printSymbolSlice(symbols []Symbol) {
-	fmt.Printf("[ ")
-	for _, sym := range symbols {
-		fmt.Printf("%s ", sym.Value)
-	}
-	fmt.Printf("]\n")
-}
-func (g *Grammar) PrintNullableTable() {
-	fmt.Println("Nullable Table:")
-
-	for nonTerminal, nullable := range g.Nullable {
-		fmt.Printf("%s: %v\n", nonTerminal, nullable)
-	}
-}
-func (g *Grammar) PrintFirstSet() {
-	fmt.Println("First Sets:")
-
-	for symbol, symbolFirstSet := range g.FirstSet {
-		// 跳过 "ε" 符号
-		if symbol.IsTerminal {
+			fmt.Println("Start symbol must be a single character.")
 			continue
 		}
-		fmt.Printf("First(%s) = {", symbol.Value)
-		first := true
-		for s, present := range symbolFirstSet {
-			if present {
-				if !first {
-					//如果不是第一个输出的符号，那么在输出符号前添加逗号和空格。
-					fmt.Print(", ")
-				}
-				fmt.Print(s.Value)
-				first = false
-			}
-		}
-		fmt.Println("}")
-	}
-}
-func (g *Grammar) PrintFollowSet() {
-	fmt.Println("Follow Sets:")
-
-	for symbol, symbolFollowSet := range g.FollowSet {
-		fmt.Printf("Follow(%s) = {", symbol.Value)
-		first := true
-		for s, present := range symbolFollowSet {
-			if present {
-				if !first {
-					//如果不是第一个输出的符号，那么在输出符号前添加逗号和空格。
-					fmt.Print(", ")
-				}
-				fmt.Print(s.Value)
-				first = false
-			}
-		}
-		fmt.Println("}")
-	}
-}
-func (g *Grammar) PrintPredict() {
-	fmt.Println("Predict Table:")
-	// 获取所有非终结符
-	nonTerminals := g.GetNonTerminals()
-	// 获取所有终结符
-	terminals := g.GetTerminals()
-	terminals = deleteSymbol(terminals, "ε")
-	terminals = addSymbol(terminals, "#", true)
-	// 使用 tabwriter 对输出进行对齐
-	w := tabwriter.NewWriter(os.Stdout, 8, 0, 2, ' ', 0)
-	// 打印表头
-	fmt.Fprint(w, "\t")
-	for _, t := range terminals {
-		fmt.Fprintf(w, "%s\t", t.Value)
-	}
-	fmt.Fprintln(w)
-	// 遍历非终结符
-	for _, nonTerminal := range nonTerminals {
-		fmt.Fprintf(w, "%s\t", nonTerminal.Value)
-		// 遍历终结符
-		for _, terminal := range terminals {
-			production, exists := g.Predict[nonTerminal][terminal]
-			if exists {
-				// 打印产生式
-				fmt.Fprintf(w, "%s -> ", production.Left.Value)
-				for _, symbol := range production.Right[0].Symbols {
-					fmt.Fprint(w, symbol.Value)
-				}
-			} else {
-				fmt.Fprint(w, "    ") // 空产生式用空格填充
-			}
-			fmt.Fprint(w, "\t")
+		if sym, err := ll1.ParseSymbol(startSymbol); err != nil || sym.IsTerminal {
+			fmt.Println("Start symbol must be a nonterminal identifier.")
+		} else {
+			startSymbolStruct = sym
+			break
 		}
-		fmt.Fprintln(w)
 	}
-	// 刷新 tabwriter 输出
-	w.Flush()
-}
-func deleteSymbol(symbols []Symbol, value string) []Symbol {
-	for i := range symbols {
-		if symbols[i].Value == value {
-			symbols = append(symbols[:i], symbols[i+1:]...)
+	// 输入产生式
+	for {
+		fmt.Print("Enter production (or q to quit): ")
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "q" || err != nil && input == "" {
 			break
 		}
-	}
-	return symbols
-}
-func addSymbol(symbols []Symbol, value string, isTerminal bool) []Symbol {
-	for i := range symbols {
-		if symbols[i].Value == value {
-			return symbols
+		var parsed []ll1.Production
+		if compact {
+			var prod ll1.Production
+			prod, err = ll1.ParseCompactProduction(input)
+			parsed = []ll1.Production{prod}
+		} else {
+			// 使用EBNF运算符时还会得到辅助产生式
+			parsed, err = ll1.ParseProductions(input)
+		}
+		if err != nil {
+			fmt.Println("Invalid production:", err)
+			continue
 		}
+		prods = append(prods, parsed...)
 	}
-	symbols = append(symbols, Symbol{Value: value, IsTerminal: isTerminal})
-	return symbols
+
+	// 创建文法 g
+	return ll1.NewGrammar(startSymbolStruct, prods)
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wrilove/Table-drives-LL-1-parser/ll1"
+)
+
+//打印专区
+
+func PrintGrammar(title string, g *ll1.Grammar) {
+	fmt.Println(title)
+	fmt.Print(g.String())
+}
+
+// PrintRewrite
+// 打印一次改写后的文法以及新引入的非终结符
+func PrintRewrite(rewrite ll1.Rewrite) {
+	fmt.Println(rewrite.Name + " grammar:")
+	for _, prod := range rewrite.Productions {
+		fmt.Println(prod)
+	}
+	if len(rewrite.Added) > 0 {
+		fmt.Print("Added nonterminals: ")
+		printSymbolSlice(rewrite.Added)
+	}
+	if len(rewrite.Removed) > 0 {
+		fmt.Print("Removed nonterminals: ")
+		printSymbolSlice(rewrite.Removed)
+	}
+}
+
+// PrintUselessSymbols
+// 打印推不出终结符串、不可达和未定义的符号
+func PrintUselessSymbols(useless ll1.UselessSymbols) {
+	fmt.Println("Useless symbols:")
+	fmt.Println(useless)
+}
+func PrintNonTerminals(g *ll1.Grammar) {
+	nonTerminals := g.GetNonTerminals()
+	fmt.Println("Nonterminals:")
+	printSymbolSlice(nonTerminals)
+}
+func PrintTerminals(g *ll1.Grammar) {
+	terminals := g.GetTerminals()
+	fmt.Println("Terminals:")
+	printSymbolSlice(terminals)
+}
+func printSymbolSlice(symbols []ll1.Symbol) {
+	fmt.Printf("[ ")
+	for _, sym := range symbols {
+		fmt.Printf("%s ", sym.Value)
+	}
+	fmt.Printf("]\n")
+}
+func PrintNullableTable(g *ll1.Grammar) {
+	fmt.Println("Nullable Table:")
+
+	for _, nonTerminal := range g.GetNonTerminals() {
+		fmt.Printf("%s: %v\n", nonTerminal.Value, g.Nullable[nonTerminal.Value])
+	}
+}
+func PrintFirstSet(g *ll1.Grammar) {
+	fmt.Println("First Sets:")
+
+	for _, nonTerminal := range g.GetNonTerminals() {
+		fmt.Printf("First(%s) = %s\n", nonTerminal.Value, g.FirstSet[nonTerminal])
+	}
+}
+func PrintFollowSet(g *ll1.Grammar) {
+	fmt.Println("Follow Sets:")
+
+	for _, nonTerminal := range g.GetNonTerminals() {
+		fmt.Printf("Follow(%s) = %s\n", nonTerminal.Value, g.FollowSet[nonTerminal])
+	}
+}
+
+// PrintSelectSet
+// 按产生式的顺序打印每个备选项的select集
+func PrintSelectSet(g *ll1.Grammar) {
+	fmt.Println("Select Sets:")
+
+	for _, prod := range g.Productions {
+		for _, alt := range prod.Right {
+			fmt.Printf("Select(%s -> %s) = %s\n", prod.Left.Value, ll1.SymbolsToString(alt.Symbols),
+				ll1.NewSymbolSet(g.Select(prod.Left, alt.Symbols)...))
+		}
+	}
+}
+
+// PrintLL1
+// 打印LL1判断结果以及每个冲突的非终结符、备选项和相交的终结符
+func PrintLL1(g *ll1.Grammar) {
+	fmt.Println(" LL1 grammar or not:")
+	conflicts := g.Conflicts()
+	resolved := 0
+	for _, c := range conflicts {
+		if c.Resolved {
+			fmt.Println("warning:", c)
+			resolved++
+		} else {
+			fmt.Println(c)
+		}
+	}
+	switch {
+	case len(conflicts) == 0:
+		fmt.Println("The grammar you entered is  the LL1 grammar,please continue")
+	case resolved == len(conflicts):
+		fmt.Printf("The grammar you entered is not the LL1 grammar, but all %d conflicts are resolved by %%prefer,please continue\n", resolved)
+	default:
+		fmt.Printf("The grammar you entered is not the LL1 grammar (%d conflicts, %d resolved)\n", len(conflicts), resolved)
+	}
+}
+func PrintPredict(g *ll1.Grammar) {
+	fmt.Println("Predict Table:")
+	// 获取所有非终结符
+	nonTerminals := g.GetNonTerminals()
+	// 获取所有终结符，最后一列是结束符
+	terminals := append(g.GetTerminals(), g.EndSymbol())
+	// 使用 tabwriter 对输出进行对齐
+	w := tabwriter.NewWriter(os.Stdout, 8, 0, 2, ' ', 0)
+	// 打印表头
+	fmt.Fprint(w, "\t")
+	for _, t := range terminals {
+		fmt.Fprintf(w, "%s\t", t.Value)
+	}
+	fmt.Fprintln(w)
+	// 遍历非终结符
+	for _, nonTerminal := range nonTerminals {
+		fmt.Fprintf(w, "%s\t", nonTerminal.Value)
+		// 遍历终结符
+		for _, terminal := range terminals {
+			production, exists := g.Predict[nonTerminal][terminal]
+			if exists {
+				// 打印产生式
+				fmt.Fprintf(w, "%s -> %s", production.Left.Value, ll1.SymbolsToString(production.Right[0].Symbols))
+			} else {
+				fmt.Fprint(w, "    ") // 空产生式用空格填充
+			}
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprintln(w)
+	}
+	// 刷新 tabwriter 输出
+	w.Flush()
+}
+
+// PrintLLk
+// 打印最小的k和LL(k)预测分析表，表的每一行是一个非终结符和它的所有向前看串
+func PrintLLk(g *ll1.Grammar, report ll1.KReport) {
+	fmt.Println(" LL(k) grammar or not:")
+	if !report.Deterministic {
+		for _, c := range report.Conflicts {
+			fmt.Println(c)
+		}
+		fmt.Printf("The grammar you entered is not LL(k) for any k <= %d\n", report.K)
+		return
+	}
+	fmt.Printf("The grammar you entered is LL(%d),please continue\n", report.K)
+	fmt.Println()
+	fmt.Printf("LL(%d) Predict Table:\n", report.K)
+	w := tabwriter.NewWriter(os.Stdout, 8, 0, 2, ' ', 0)
+	for _, nonTerminal := range g.GetNonTerminals() {
+		for _, lookahead := range report.Table.Lookaheads(nonTerminal) {
+			production, _ := report.Table.Lookup(nonTerminal, lookahead)
+			fmt.Fprintf(w, "%s\t%s\t%s -> %s\n", nonTerminal.Value, ll1.JoinSymbols(lookahead),
+				production.Left.Value, ll1.SymbolsToString(production.Right[0].Symbols))
+		}
+	}
+	w.Flush()
+}
+
+// PrintParse
+// 按步骤打印分析过程，使用 tabwriter 对输出进行对齐
+func PrintParse(input string, result ll1.ParseResult) {
+	fmt.Printf("%s的分析过程\n", input)
+	w := tabwriter.NewWriter(os.Stdout, 8, 0, 2, ' ', 0)
+	for _, step := range result.Steps {
+		printStep(w, step.Number, step.AnalysisStack, step.Input)
+		w.Flush()
+		fmt.Printf("\t")
+		switch step.Action {
+		case ll1.ActionAccept:
+			fmt.Println("输入的字符串分析成功.")
+		case ll1.ActionMatch:
+			fmt.Printf("匹配成功%s.\n", step.Input[0].Text)
+		case ll1.ActionExpand:
+			//打印使用的产生式
+			fmt.Printf("使用产生式 %s -> ", step.Production.Left.Value)
+			for _, sym := range step.Production.Right[0].Symbols {
+				fmt.Printf("%s ", sym.Value)
+			}
+			fmt.Println()
+		case ll1.ActionError:
+			fmt.Printf("分析结束，共发现%d处错误.\n", len(result.Errors))
+		case ll1.ActionSkip:
+			fmt.Printf("匹配失败，跳过%s.\n", tokensToString(step.Input[:1]))
+		case ll1.ActionPop:
+			fmt.Printf("匹配失败，弹出%s.\n", step.AnalysisStack[len(step.AnalysisStack)-1].Value)
+		}
+	}
+	if len(result.Errors) > 0 {
+		fmt.Println()
+		fmt.Println("Syntax errors:")
+		for _, err := range result.Errors {
+			fmt.Println(err)
+		}
+	}
+}
+func printStep(w *tabwriter.Writer, step int, analysisStack []ll1.Symbol, characterStack []ll1.Token) {
+	fmt.Fprintf(w, "%d\t%s\t%s", step, ll1.SymbolsToString(analysisStack), tokensToString(characterStack))
+}
+
+// tokensToString
+// 拼接剩余输入中单词的原文，输入末尾的结束符没有原文，用终结符代替。
+// 有多字符的终结符时用空格分隔，避免 2 3 这样的单词连在一起
+func tokensToString(tokens []ll1.Token) string {
+	values := make([]string, len(tokens))
+	kinds := make([]string, len(tokens))
+	for i, t := range tokens {
+		values[i] = t.Text
+		if t.Text == "" {
+			values[i] = t.Kind
+		}
+		kinds[i] = t.Kind
+	}
+	if strings.Contains(ll1.JoinSymbols(kinds), " ") {
+		return strings.Join(values, " ")
+	}
+	return ll1.JoinSymbols(values)
+}
+
+// PrintTree
+// 打印分析树和最左推导
+func PrintTree(tree *ll1.Node) {
+	fmt.Println("Parse tree:")
+	fmt.Print(tree)
+	fmt.Println("Leftmost derivation:")
+	for i, form := range tree.LeftmostDerivation() {
+		if i == 0 {
+			fmt.Printf("   %s\n", ll1.SymbolsToString(form))
+		} else {
+			fmt.Printf("=> %s\n", ll1.SymbolsToString(form))
+		}
+	}
+}